@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// assertion is a --assert expression such as "skipped<=10" or
+// "tests(project=webkit)>=500": a metric, optionally counted over the tests
+// matching some query terms, compared with a number.
+type assertion struct {
+	Expr      string
+	Metric    string
+	Selectors []termExpr
+	Op        string
+	Value     int
+}
+
+// assertPattern splits an assertion into its metric, selectors and
+// comparison, allowing whitespace around the operator and parentheses.
+var assertPattern = regexp.MustCompile(`^\s*([a-zA-Z]+)\s*(?:\((.*)\))?\s*(<=|>=|==|!=|<|>)\s*(\d+)\s*$`)
+
+// assertMetrics are the counts an assertion can check. All but files and
+// specs count tests, i.e. spec and project pairs.
+var assertMetrics = []string{"tests", "specs", "files", "skipped", "fixme", "fail", "untagged", "passed", "failed", "flaky"}
+
+func parseAssertion(expr string) (assertion, error) {
+	m := assertPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return assertion{}, fmt.Errorf("invalid assertion %q, expected e.g. skipped<=10 or tests(project=webkit)>=500", expr)
+	}
+	a := assertion{Metric: m[1], Op: m[3]}
+	if !slices.Contains(assertMetrics, a.Metric) {
+		return a, fmt.Errorf("unknown metric %q in %q, expected one of %s", a.Metric, expr, strings.Join(assertMetrics, ", "))
+	}
+	a.Value, _ = strconv.Atoi(m[4])
+
+	var selectors []string
+	if strings.TrimSpace(m[2]) != "" {
+		for _, selector := range splitSelectors(m[2]) {
+			field, raw, ok := strings.Cut(selector, "=")
+			field, raw = strings.TrimSpace(field), strings.TrimSpace(raw)
+			value := unquoteSelector(raw)
+			if !ok || value == "" {
+				return a, fmt.Errorf("invalid selector %q in %q, expected field=value", strings.TrimSpace(selector), expr)
+			}
+			if !queryFields[field] {
+				return a, fmt.Errorf("unknown field %q in %q, expected one of %s", field, expr, strings.Join(sortedKeys(queryFields), ", "))
+			}
+			term, err := newTermExpr(field, value)
+			if err != nil {
+				return a, fmt.Errorf("%v in %q", err, expr)
+			}
+			a.Selectors = append(a.Selectors, term)
+			selectors = append(selectors, field+"="+raw)
+		}
+	}
+
+	a.Expr = a.Metric
+	if len(selectors) > 0 {
+		a.Expr += "(" + strings.Join(selectors, ",") + ")"
+	}
+	a.Expr += a.Op + m[4]
+	return a, nil
+}
+
+// splitSelectors splits selectors at the commas outside of quoted values and
+// /regular expressions/.
+func splitSelectors(s string) []string {
+	var selectors []string
+	var quoted, regex, escaped, afterEquals bool
+	start := 0
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quoted:
+			quoted = r != '"'
+		case regex:
+			regex = r != '/'
+		case r == '"':
+			quoted = true
+		case r == '/' && afterEquals:
+			regex = true
+		case r == ',':
+			selectors = append(selectors, s[start:i])
+			start = i + 1
+		}
+		if r == '=' {
+			afterEquals = true
+		} else if r != ' ' {
+			afterEquals = false
+		}
+	}
+	return append(selectors, s[start:])
+}
+
+// unquoteSelector reads a quoted value as --query does, e.g. "add item".
+func unquoteSelector(value string) string {
+	if !strings.HasPrefix(value, `"`) {
+		return value
+	}
+	tokens, err := tokenizeQuery(value)
+	if err != nil || len(tokens) != 2 || tokens[0].kind != tokenTerm || tokens[0].field != "" {
+		return value
+	}
+	return tokens[0].value
+}
+
+// checkAssertUsage rejects --assert with the commands and --workspace, which
+// have their own output and exit status.
+func checkAssertUsage(command string, workspace bool) error {
+	if workspace {
+		return fmt.Errorf("--assert cannot be combined with --workspace")
+	}
+	if command != "" {
+		return fmt.Errorf("--assert cannot be combined with 'pwtree %s', run them as separate steps", command)
+	}
+	return nil
+}
+
+func parseAssertions(exprs []string) ([]assertion, error) {
+	var assertions []assertion
+	for _, expr := range exprs {
+		a, err := parseAssertion(expr)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, a)
+	}
+	return assertions, nil
+}
+
+// measure counts the metric over the tests of the report matching the
+// assertion's selectors, with the totals of the tree.
+func (a assertion) measure(report PlaywrightJSON) int {
+	var expr queryExpr = metricExpr{a.Metric}
+	for _, selector := range a.Selectors {
+		expr = andExpr{selector, expr}
+	}
+	report.Suites = filterSuitesByQuery(report.Suites, expr)
+	root := buildNodeTree(report)
+
+	tests, files := root.totals()
+	switch a.Metric {
+	case "files":
+		return files
+	case "specs":
+		return root.specCount()
+	}
+	return tests
+}
+
+// metricExpr holds for the tests an assertion's metric counts.
+type metricExpr struct{ metric string }
+
+func (e metricExpr) eval(t *queryTarget) bool {
+	switch e.metric {
+	case "skipped":
+		return slices.Contains(t.Annotations, "skip")
+	case "fixme", "fail":
+		return slices.Contains(t.Annotations, e.metric)
+	case "untagged":
+		return len(t.Tags) == 0
+	case "passed", "failed", "flaky":
+		return t.Outcome == e.metric
+	}
+	return true
+}
+
+func (a assertion) holds(actual int) bool {
+	switch a.Op {
+	case "<=":
+		return actual <= a.Value
+	case ">=":
+		return actual >= a.Value
+	case "<":
+		return actual < a.Value
+	case ">":
+		return actual > a.Value
+	case "!=":
+		return actual != a.Value
+	}
+	return actual == a.Value
+}
+
+// checkAssertions evaluates the assertions against the report and renders
+// one line per assertion with its actual count. ok is false when any fails.
+func checkAssertions(assertions []assertion, report PlaywrightJSON, styles map[string]lipgloss.Style) (out string, ok bool) {
+	var b strings.Builder
+	failed := 0
+	for _, a := range assertions {
+		actual := a.measure(report)
+		mark := styles["passed"].Render("✔")
+		if !a.holds(actual) {
+			mark = styles["failed"].Render("✘")
+			failed++
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", mark, a.Expr, styles["counter"].Render(fmt.Sprintf("(actual: %d)", actual)))
+	}
+
+	b.WriteString("\n")
+	if failed > 0 {
+		b.WriteString(styles["failed"].Render(fmt.Sprintf("%d of %d assertion%s failed", failed, len(assertions), pluralize(len(assertions)))))
+	} else {
+		b.WriteString(styles["passed"].Render(fmt.Sprintf("%d assertion%s passed", len(assertions), pluralize(len(assertions)))))
+	}
+	return b.String() + "\n", failed == 0
+}
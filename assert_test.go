@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestParseAssertion(t *testing.T) {
+	a, err := parseAssertion("tests(project=webkit, tag=@smoke) >= 500")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Expr != "tests(project=webkit,tag=@smoke)>=500" || a.Metric != "tests" || a.Op != ">=" || a.Value != 500 || len(a.Selectors) != 2 {
+		t.Errorf("Unexpected assertion %+v", a)
+	}
+
+	for _, expr := range []string{"skipped", "skipped<=ten", "slow<=1", "tests(webkit)>=1", "tests(owner=me)>=1", "tests(annotation=todo)>=1"} {
+		if _, err := parseAssertion(expr); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
+
+func TestParseAssertion_Values(t *testing.T) {
+	report := PlaywrightJSON{Suites: []Suite{{Title: "cart.spec.ts", File: "cart.spec.ts", Specs: []Spec{
+		{Title: "adds an item", File: "cart.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium"}}},
+		{Title: "aa", File: "cart.spec.ts", Line: 8, Tests: []TestInstance{{ProjectName: "chromium"}}},
+	}}}}
+	cases := []struct {
+		expr, canonical string
+		expected        int
+	}{
+		{"tests(title=adds an item) >= 1", "tests(title=adds an item)>=1", 1},
+		{`tests( title = "adds an item" )>=1`, `tests(title="adds an item")>=1`, 1},
+		{"tests(title=/^a{1,2}$/, project=chromium)>=1", "tests(title=/^a{1,2}$/,project=chromium)>=1", 1},
+	}
+	for _, c := range cases {
+		a, err := parseAssertion(c.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.expr, err)
+		}
+		if a.Expr != c.canonical {
+			t.Errorf("Expected %q, got %q", c.canonical, a.Expr)
+		}
+		if got := a.measure(report); got != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.expr, c.expected, got)
+		}
+	}
+}
+
+func TestCheckAssertUsage(t *testing.T) {
+	if err := checkAssertUsage("", false); err != nil {
+		t.Errorf("Expected --assert to be allowed with the tree, got %v", err)
+	}
+	for _, command := range []string{"check", "lint", "diff", "snapshot"} {
+		if err := checkAssertUsage(command, false); err == nil || !strings.Contains(err.Error(), command) {
+			t.Errorf("Expected --assert to be rejected with %s, got %v", command, err)
+		}
+	}
+	if err := checkAssertUsage("", true); err == nil {
+		t.Errorf("Expected --assert to be rejected with --workspace")
+	}
+}
+
+func TestAssertionMeasure(t *testing.T) {
+	report := sortFixture()
+	cases := []struct {
+		expr     string
+		expected int
+	}{
+		{"tests>=0", 6},
+		{"specs>=0", 5},
+		{"files>=0", 2},
+		{"fixme>=0", 1},
+		{"skipped>=0", 0},
+		{"untagged>=0", 3},
+		{"tests(project=webkit)>=0", 1},
+		{"tests(project=chromium,file=b.spec.ts)>=0", 4},
+		{"specs(tag=@smoke)>=0", 1},
+	}
+	for _, c := range cases {
+		a, err := parseAssertion(c.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.expr, err)
+		}
+		if got := a.measure(report); got != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.expr, c.expected, got)
+		}
+	}
+
+	results := loadResultsFixture(t)
+	for expr, expected := range map[string]int{"passed>=0": 5, "failed>=0": 2, "flaky>=0": 1} {
+		a, _ := parseAssertion(expr)
+		if got := a.measure(results); got != expected {
+			t.Errorf("%s: expected %d, got %d", expr, expected, got)
+		}
+	}
+}
+
+func TestCheckAssertions(t *testing.T) {
+	assertions, err := parseAssertions([]string{"fixme==0", "tests>=5", "untagged<3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, ok := checkAssertions(assertions, sortFixture(), map[string]lipgloss.Style{})
+	if ok {
+		t.Errorf("Expected the assertions to fail")
+	}
+	for _, line := range []string{
+		"✘ fixme==0 (actual: 1)",
+		"✔ tests>=5 (actual: 6)",
+		"✘ untagged<3 (actual: 3)",
+		"2 of 3 assertions failed",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q, got\n%s", line, out)
+		}
+	}
+
+	if out, ok := checkAssertions(assertions[1:2], sortFixture(), map[string]lipgloss.Style{}); !ok || !strings.Contains(out, "1 assertion passed") {
+		t.Errorf("Expected the assertion to pass, got\n%s", out)
+	}
+}
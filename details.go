@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// stackLocationPattern matches "at file:line:column" frames of a stack trace,
+// with or without a function name in front.
+var stackLocationPattern = regexp.MustCompile(`^\s*at (?:.*\()?(.+):(\d+):(\d+)\)?$`)
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// attachResults adds the attempts of each run spec beneath it, limited to the
+// spec's projects so project groups only show their own results.
+func attachResults(n *treeNode, rootDir string) {
+	if n.Kind != specNode {
+		for _, child := range n.Children {
+			attachResults(child, rootDir)
+		}
+		return
+	}
+
+	for _, project := range sortedKeys(n.Spec.Projects) {
+		for _, result := range n.Spec.Results[project] {
+			if node := buildResultNode(project, result, rootDir); node != nil {
+				n.Children = append(n.Children, node)
+			}
+		}
+	}
+}
+
+// buildResultNode builds the node of one attempt, or nil if it has nothing to show.
+func buildResultNode(project string, result TestResult, rootDir string) *treeNode {
+	duration := time.Duration(result.Duration) * time.Millisecond
+	node := &treeNode{Kind: resultNode, Status: result.Status, Duration: duration}
+
+	if *showErrors {
+		for _, e := range result.Errors {
+			node.Children = append(node.Children, buildErrorNode(e, rootDir))
+		}
+	}
+	if *showSteps {
+		for _, step := range result.Steps {
+			node.Children = append(node.Children, buildStepNode(step))
+		}
+	}
+	if *showAttachments {
+		for _, a := range result.Attachments {
+			node.Children = append(node.Children, &treeNode{
+				Kind:   detailNode,
+				Detail: "attachment",
+				Title:  a.Name,
+				File:   attachmentPath(a, rootDir),
+			})
+		}
+	}
+
+	if len(node.Children) == 0 {
+		return nil
+	}
+
+	node.Title = project
+	if result.Retry > 0 {
+		node.Title += fmt.Sprintf(" retry %d", result.Retry)
+	}
+	node.Title += fmt.Sprintf(": %s %s", result.Status, formatDuration(duration))
+	return node
+}
+
+// buildStepNode mirrors the step hierarchy; steps that threw are marked
+// failed so the failing path stands out.
+func buildStepNode(step TestStep) *treeNode {
+	node := &treeNode{
+		Kind:     detailNode,
+		Detail:   "step",
+		Title:    step.Title,
+		Duration: time.Duration(step.Duration) * time.Millisecond,
+	}
+	if step.Error != nil {
+		node.Status = "failed"
+	}
+	for _, child := range step.Steps {
+		node.Children = append(node.Children, buildStepNode(child))
+	}
+	return node
+}
+
+// buildErrorNode shows an error's message, with its location and code snippet as
+// children.
+func buildErrorNode(e TestError, rootDir string) *treeNode {
+	node := &treeNode{Kind: detailNode, Detail: "error", Title: trimLines(stripANSI(e.Message))}
+
+	if location := errorLocation(e, rootDir); location != "" {
+		node.Children = append(node.Children, &treeNode{Kind: detailNode, Detail: "location", Title: "at " + location})
+	}
+	if snippet := trimLines(stripANSI(e.Snippet)); snippet != "" {
+		node.Children = append(node.Children, &treeNode{Kind: detailNode, Detail: "snippet", Title: snippet})
+	}
+	return node
+}
+
+// errorLocation returns file:line:column relative to the report's root
+// directory, from the error's location or else the first frame of its stack
+// outside node_modules.
+func errorLocation(e TestError, rootDir string) string {
+	if e.Location != nil {
+		return fmt.Sprintf("%s:%d:%d", relativePath(e.Location.File, rootDir), e.Location.Line, e.Location.Column)
+	}
+	for _, line := range strings.Split(stripANSI(e.Stack), "\n") {
+		m := stackLocationPattern.FindStringSubmatch(line)
+		if m == nil || strings.Contains(m[1], "node_modules") {
+			continue
+		}
+		return fmt.Sprintf("%s:%s:%s", relativePath(m[1], rootDir), m[2], m[3])
+	}
+	return ""
+}
+
+// attachmentPath returns the attachment's path relative to the current
+// directory, or "" for inline attachments. Relative paths are resolved
+// against the report's root directory.
+func attachmentPath(a Attachment, rootDir string) string {
+	if a.Path == "" {
+		return ""
+	}
+	path := a.Path
+	if !filepath.IsAbs(path) && rootDir != "" {
+		path = filepath.Join(rootDir, path)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		return relativePath(path, cwd)
+	}
+	return path
+}
+
+func relativePath(file, rootDir string) string {
+	if rootDir == "" || !filepath.IsAbs(file) {
+		return file
+	}
+	if rel, err := filepath.Rel(rootDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
+
+// trimLines drops trailing whitespace and blank leading and trailing lines.
+func trimLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
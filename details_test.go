@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestErrorLocation(t *testing.T) {
+	cases := []struct {
+		err      TestError
+		expected string
+	}{
+		{TestError{Location: &ErrorLocation{File: "/work/shop/tests/cart.spec.ts", Line: 18, Column: 45}}, "cart.spec.ts:18:45"},
+		{TestError{Location: &ErrorLocation{File: "/elsewhere/helpers.ts", Line: 3, Column: 1}}, "/elsewhere/helpers.ts:3:1"},
+		{TestError{Stack: "Error: boom\n    at helper (/work/shop/node_modules/lib/index.js:1:2)\n    at /work/shop/tests/cart.spec.ts:20:7"}, "cart.spec.ts:20:7"},
+		{TestError{Stack: "Error: boom"}, ""},
+	}
+	for _, c := range cases {
+		if got := errorLocation(c.err, "/work/shop/tests"); got != c.expected {
+			t.Errorf("Expected %q, got %q", c.expected, got)
+		}
+	}
+}
+
+func TestBuildTreeView_Errors(t *testing.T) {
+	original := *showErrors
+	defer func() { *showErrors = original }()
+	*showErrors = true
+
+	root := buildNodeTree(loadResultsFixture(t))
+	output := renderTreeView(root, map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+
+	for _, want := range []string{
+		"chromium: failed 6.1s",
+		"chromium retry 1: failed 6.0s",
+		"Error: expect(locator).toHaveText(expected)",
+		"at checkout.spec.ts:18:45",
+		"> 18 |   await expect(page.getByTestId('cart-total')).toHaveText('$42.00');",
+		"chromium: timedOut 30.0s",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected ANSI codes to be stripped from messages")
+	}
+	if strings.Contains(output, "firefox:") {
+		t.Errorf("Expected no results for passing projects, got:\n%s", output)
+	}
+	if tests, _ := root.totals(); tests != 10 {
+		t.Errorf("Expected results not to count as tests, got %d", tests)
+	}
+}
+
+func TestBuildTreeView_Steps(t *testing.T) {
+	original := *showSteps
+	defer func() { *showSteps = original }()
+	*showSteps = true
+
+	root := buildNodeTree(loadResultsFixture(t))
+	output := renderTreeView(root, map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+
+	for _, want := range []string{
+		"chromium retry 1: passed 1.2s",
+		"firefox: passed 2.3s",
+		"Add items 900ms",
+		"page.goto(/products) 400ms",
+		"expect(locator).toHaveText(expected) ✘ 5.0s",
+		"locator.fill(getByLabel('Coupon')) ✘ 29.8s",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Error:") {
+		t.Errorf("Expected no error messages without --errors, got:\n%s", output)
+	}
+}
+
+func TestBuildTreeView_Attachments(t *testing.T) {
+	original := *showAttachments
+	defer func() { *showAttachments = original }()
+	*showAttachments = true
+
+	output := renderTreeView(buildNodeTree(loadResultsFixture(t)), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+
+	for _, want := range []string{
+		"trace /work/shop/test-results/checkout-cart-chromium-retry1/trace.zip",
+		"screenshot /work/shop/test-results/login-logs-in-chromium/test-failed-1.png",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,694 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inventoryEntry is one spec of a suite inventory. Specs of two inventories
+// are the same spec when their keys, made of the file, describe titles and
+// title, are equal.
+type inventoryEntry struct {
+	Key           string
+	ID            string
+	File          string
+	Line          int
+	Describes     []string
+	DescribeLines []int
+	Title         string
+	Tags          []string
+	Projects      []string
+	Annotations   []string
+	// ProjectAnnotations holds the annotations of each project, as a spec
+	// may be skipped in some projects only.
+	ProjectAnnotations map[string][]string
+}
+
+// specChange is a spec that was added, removed, renamed, moved to another
+// file or describe, or otherwise changed between two inventories. Old is nil
+// for added specs and New for removed ones.
+type specChange struct {
+	Kind    string
+	Old     *inventoryEntry
+	New     *inventoryEntry
+	Changes []fieldChange
+}
+
+// fieldChange lists the values added to and removed from a field of a
+// changed spec: its tags, projects, annotations, line, title or location.
+type fieldChange struct {
+	Field   string
+	Added   []string
+	Removed []string
+}
+
+func (c fieldChange) String() string {
+	switch c.Field {
+	case "line":
+		return fmt.Sprintf("line %s → %s", c.Removed[0], c.Added[0])
+	case "title":
+		return "renamed from " + c.Removed[0]
+	case "location":
+		return "from " + c.Removed[0]
+	}
+	var parts []string
+	for _, v := range c.Added {
+		parts = append(parts, "+"+v)
+	}
+	for _, v := range c.Removed {
+		parts = append(parts, "-"+v)
+	}
+	return c.Field + " " + strings.Join(parts, " ")
+}
+
+var diffMarkers = map[string]string{"added": "+", "removed": "-", "changed": "~"}
+
+// inventory flattens a report into its specs, in report order, with the
+// projects, tags and annotations of each spec sorted. Reports listing a spec
+// once per project, with the same title and line, give one entry for it.
+func inventory(report PlaywrightJSON) []inventoryEntry {
+	var entries []inventoryEntry
+
+	var walk func(suites []Suite, describes []string, lines []int)
+	walk = func(suites []Suite, describes []string, lines []int) {
+		for _, suite := range suites {
+			path, pathLines := describes, lines
+			if suite.Title != "" && suite.Title != suite.File {
+				path = append(slices.Clone(describes), suite.Title)
+				pathLines = append(slices.Clone(lines), suiteLine(suite))
+			}
+			bySpec := map[string]int{}
+			for _, spec := range suite.Specs {
+				key := fmt.Sprintf("%s:%d:%s", spec.File, spec.Line, spec.Title)
+				i, ok := bySpec[key]
+				if !ok {
+					i = len(entries)
+					bySpec[key] = i
+					entries = append(entries, inventoryEntry{
+						ID:            spec.ID,
+						File:          spec.File,
+						Line:          spec.Line,
+						Describes:     path,
+						DescribeLines: pathLines,
+						Title:         spec.Title,
+					})
+				}
+				entry := &entries[i]
+				entry.Tags = sortedUnique(append(entry.Tags, spec.Tags...))
+				for _, test := range spec.Tests {
+					entry.Projects = sortedUnique(append(entry.Projects, test.ProjectName))
+					for _, ann := range test.Annotations {
+						entry.Annotations = sortedUnique(append(entry.Annotations, ann.Type))
+						if entry.ProjectAnnotations == nil {
+							entry.ProjectAnnotations = map[string][]string{}
+						}
+						entry.ProjectAnnotations[test.ProjectName] = sortedUnique(append(entry.ProjectAnnotations[test.ProjectName], ann.Type))
+					}
+				}
+			}
+			walk(suite.Suites, path, pathLines)
+		}
+	}
+	walk(report.Suites, nil, nil)
+
+	assignKeys(entries)
+	return entries
+}
+
+// assignKeys sets the key of each entry. Specs sharing a title in the same
+// describe are told apart by their position.
+func assignKeys(entries []inventoryEntry) {
+	seen := map[string]int{}
+	for i := range entries {
+		key := entries[i].location() + " › " + entries[i].Title
+		seen[key]++
+		if seen[key] > 1 {
+			key += fmt.Sprintf(" #%d", seen[key])
+		}
+		entries[i].Key = key
+	}
+}
+
+func sortedUnique(values []string) []string {
+	out := slices.Clone(values)
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// diffInventories matches the specs of two inventories by key, then pairs
+// the remaining ones that were renamed or moved, and returns the added,
+// removed and changed specs: added and changed specs in the order of the new
+// inventory, followed by the removed ones.
+func diffInventories(old, new []inventoryEntry) []specChange {
+	oldByKey := map[string]*inventoryEntry{}
+	for i := range old {
+		oldByKey[old[i].Key] = &old[i]
+	}
+	matches := map[*inventoryEntry]*inventoryEntry{}
+	matched := map[*inventoryEntry]bool{}
+	var added []*inventoryEntry
+	for i := range new {
+		if before, ok := oldByKey[new[i].Key]; ok {
+			matches[&new[i]] = before
+			matched[before] = true
+		} else {
+			added = append(added, &new[i])
+		}
+	}
+	var removed []*inventoryEntry
+	for i := range old {
+		if !matched[&old[i]] {
+			removed = append(removed, &old[i])
+		}
+	}
+	for after, before := range pairMovedSpecs(removed, added) {
+		matches[after] = before
+		matched[before] = true
+	}
+
+	var changes []specChange
+	for i := range new {
+		entry := &new[i]
+		before, ok := matches[entry]
+		if !ok {
+			changes = append(changes, specChange{Kind: "added", New: entry})
+			continue
+		}
+		if fields := compareEntries(before, entry); len(fields) > 0 {
+			changes = append(changes, specChange{Kind: changeKind(fields), Old: before, New: entry, Changes: fields})
+		}
+	}
+	for i := range old {
+		if !matched[&old[i]] {
+			changes = append(changes, specChange{Kind: "removed", Old: &old[i]})
+		}
+	}
+	return changes
+}
+
+func changeKind(fields []fieldChange) string {
+	for _, field := range fields {
+		switch field.Field {
+		case "title":
+			return "renamed"
+		case "location":
+			return "moved"
+		}
+	}
+	return "changed"
+}
+
+// renameSimilarity is the least title similarity, from 0 to 1, for a spec
+// added near a removed one to count as renamed.
+const renameSimilarity = 0.75
+
+// renameLineDistance is how far, in lines, a renamed spec may move when it
+// stays in the same file but not in the same describe.
+const renameLineDistance = 10
+
+// pairMovedSpecs matches removed specs with the added specs they most likely
+// became, returning the removed spec of each added one. In order of
+// preference, a pair has the same spec id, the same title in another file or
+// describe, or a similar title in the neighborhood of the same file.
+func pairMovedSpecs(removed, added []*inventoryEntry) map[*inventoryEntry]*inventoryEntry {
+	type candidate struct {
+		old, new *inventoryEntry
+		score    float64
+	}
+	var candidates []candidate
+	for _, before := range removed {
+		for _, after := range added {
+			if score := moveScore(before, after); score > 0 {
+				candidates = append(candidates, candidate{before, after, score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	pairs := map[*inventoryEntry]*inventoryEntry{}
+	used := map[*inventoryEntry]bool{}
+	for _, c := range candidates {
+		if used[c.old] || used[c.new] {
+			continue
+		}
+		used[c.old], used[c.new] = true, true
+		pairs[c.new] = c.old
+	}
+	return pairs
+}
+
+// moveScore rates how likely the added spec is the removed one, or returns 0
+// when it is not a candidate. Ties are broken by proximity.
+func moveScore(before, after *inventoryEntry) float64 {
+	sameFile := before.File == after.File
+	proximity := 1 / float64(2+abs(before.Line-after.Line))
+	if sameFile {
+		proximity += 0.5
+	}
+	switch {
+	case before.ID != "" && before.ID == after.ID:
+		return 4 + proximity
+	case before.Title == after.Title:
+		return 2 + proximity
+	case sameFile && (slices.Equal(before.Describes, after.Describes) || abs(before.Line-after.Line) <= renameLineDistance):
+		if similarity := titleSimilarity(before.Title, after.Title); similarity >= renameSimilarity {
+			return similarity + proximity/2
+		}
+	}
+	return 0
+}
+
+// titleSimilarity is 1 minus the edit distance of the titles relative to the
+// longer one.
+func titleSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func compareEntries(old, new *inventoryEntry) []fieldChange {
+	var fields []fieldChange
+	for _, field := range []struct {
+		name     string
+		old, new []string
+	}{
+		{"tags", old.Tags, new.Tags},
+		{"projects", old.Projects, new.Projects},
+		{"annotations", old.Annotations, new.Annotations},
+	} {
+		added, removed := setDifference(field.new, field.old), setDifference(field.old, field.new)
+		if len(added) > 0 || len(removed) > 0 {
+			fields = append(fields, fieldChange{Field: field.name, Added: added, Removed: removed})
+		}
+	}
+	if old.Title != new.Title {
+		fields = append([]fieldChange{{Field: "title", Added: []string{new.Title}, Removed: []string{old.Title}}}, fields...)
+	}
+	// The line of a spec moved elsewhere says nothing about it, and lockfiles
+	// have no lines.
+	if location := old.location(); location != new.location() {
+		fields = append(fields, fieldChange{Field: "location", Added: []string{new.location()}, Removed: []string{location}})
+	} else if old.Line != new.Line && old.Line != 0 && new.Line != 0 {
+		fields = append(fields, fieldChange{
+			Field:   "line",
+			Added:   []string{fmt.Sprint(new.Line)},
+			Removed: []string{fmt.Sprint(old.Line)},
+		})
+	}
+	return fields
+}
+
+// location is the file and describe titles of the spec, e.g.
+// "cart.spec.ts › Cart".
+func (e *inventoryEntry) location() string {
+	return strings.Join(append([]string{e.File}, e.Describes...), " › ")
+}
+
+// setDifference returns the values of a that are not in b.
+func setDifference(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// entry returns the current state of the spec, or its last one if it was
+// removed.
+func (c specChange) entry() *inventoryEntry {
+	if c.New != nil {
+		return c.New
+	}
+	return c.Old
+}
+
+// diffReport rebuilds a report holding only the changed specs, so the diff
+// is rendered with the same sorting and grouping as any other tree.
+func diffReport(changes []specChange) PlaywrightJSON {
+	var report PlaywrightJSON
+	for _, change := range changes {
+		entry := change.entry()
+		suites := &report.Suites
+		var suite *Suite
+		for i, title := range append([]string{entry.File}, entry.Describes...) {
+			suite = findSuite(suites, title)
+			if suite == nil {
+				line := 0
+				if i > 0 && i <= len(entry.DescribeLines) {
+					line = entry.DescribeLines[i-1]
+				}
+				*suites = append(*suites, Suite{Title: title, File: entry.File, Line: line})
+				suite = &(*suites)[len(*suites)-1]
+			}
+			suites = &suite.Suites
+		}
+
+		spec := Spec{ID: entry.ID, Title: entry.Title, File: entry.File, Line: entry.Line, Tags: entry.Tags}
+		for _, project := range entry.Projects {
+			test := TestInstance{ProjectName: project}
+			for _, ann := range entry.ProjectAnnotations[project] {
+				test.Annotations = append(test.Annotations, Annotation{Type: ann})
+			}
+			spec.Tests = append(spec.Tests, test)
+		}
+		suite.Specs = append(suite.Specs, spec)
+	}
+	return report
+}
+
+func findSuite(suites *[]Suite, title string) *Suite {
+	for i := range *suites {
+		if (*suites)[i].Title == title {
+			return &(*suites)[i]
+		}
+	}
+	return nil
+}
+
+// buildDiffTree renders the changed specs as a tree, each spec node carrying
+// its change. Nodes are matched with their changes by inventory key, as
+// lockfile entries have no lines.
+func buildDiffTree(changes []specChange) *treeNode {
+	byKey := map[string]*specChange{}
+	for i := range changes {
+		byKey[changes[i].entry().Key] = &changes[i]
+	}
+
+	// Specs sharing a title in the same describe are numbered in order, as
+	// assignKeys does.
+	root := buildNodeTree(diffReport(changes))
+	seen := map[string]int{}
+	var mark func(n *treeNode, describes []string)
+	mark = func(n *treeNode, describes []string) {
+		switch n.Kind {
+		case fileNode:
+			describes = nil
+		case suiteNode:
+			describes = append(slices.Clone(describes), n.Title)
+		case specNode:
+			key := strings.Join(append(append([]string{n.Spec.File}, describes...), n.Spec.Title), " › ")
+			seen[key]++
+			if seen[key] > 1 {
+				key += fmt.Sprintf(" #%d", seen[key])
+			}
+			n.Change = byKey[key]
+		}
+		for _, child := range n.Children {
+			mark(child, describes)
+		}
+	}
+	mark(root, nil)
+	return root
+}
+
+// diffSummary counts the specs of each kind of change, and the tests added
+// or removed with them, one line per category.
+func diffSummary(old, new []inventoryEntry, changes []specChange) []string {
+	testCount := func(entries []inventoryEntry) int {
+		total := 0
+		for _, e := range entries {
+			total += len(e.Projects)
+		}
+		return total
+	}
+	before, after := testCount(old), testCount(new)
+	lines := []string{fmt.Sprintf("Tests: %d → %d (%+d)", before, after, after-before)}
+	if len(changes) == 0 {
+		return append(lines, "No differences")
+	}
+
+	specs := map[string]int{}
+	tests := map[string]int{}
+	fields := map[string]int{}
+	annotations := map[string]map[string]int{"+": {}, "-": {}}
+	var projectsAdded, projectsRemoved int
+	for _, change := range changes {
+		specs[change.Kind]++
+		tests[change.Kind] += len(change.entry().Projects)
+		for _, field := range change.Changes {
+			fields[field.Field]++
+			switch field.Field {
+			case "projects":
+				projectsAdded += len(field.Added)
+				projectsRemoved += len(field.Removed)
+			case "annotations":
+				for _, a := range field.Added {
+					annotations["+"][a]++
+				}
+				for _, a := range field.Removed {
+					annotations["-"][a]++
+				}
+			}
+		}
+	}
+
+	for _, kind := range []string{"added", "removed", "renamed", "moved", "changed"} {
+		if specs[kind] == 0 {
+			continue
+		}
+		line := fmt.Sprintf("%s: %d spec%s", strings.ToUpper(kind[:1])+kind[1:], specs[kind], pluralize(specs[kind]))
+		if kind == "added" || kind == "removed" {
+			line += fmt.Sprintf(" (%d test%s)", tests[kind], pluralize(tests[kind]))
+		}
+		lines = append(lines, line)
+	}
+	if fields["tags"] > 0 {
+		lines = append(lines, fmt.Sprintf("  tags changed: %d", fields["tags"]))
+	}
+	if fields["projects"] > 0 {
+		lines = append(lines, fmt.Sprintf("  projects changed: %d (+%d/-%d tests)", fields["projects"], projectsAdded, projectsRemoved))
+	}
+	for _, sign := range []string{"+", "-"} {
+		counts := annotations[sign]
+		if len(counts) == 0 {
+			continue
+		}
+		var parts []string
+		total := 0
+		for _, a := range sortedKeys(countKeys(counts)) {
+			parts = append(parts, fmt.Sprintf("%s %d", a, counts[a]))
+			total += counts[a]
+		}
+		verb := "added"
+		if sign == "-" {
+			verb = "removed"
+		}
+		lines = append(lines, fmt.Sprintf("  annotations %s: %d (%s)", verb, total, strings.Join(parts, ", ")))
+	}
+	if fields["line"] > 0 {
+		lines = append(lines, fmt.Sprintf("  line changed: %d", fields["line"]))
+	}
+	return lines
+}
+
+func countKeys(counts map[string]int) map[string]bool {
+	keys := map[string]bool{}
+	for k := range counts {
+		keys[k] = true
+	}
+	return keys
+}
+
+// diffLabel marks a spec with +, - or ~, or as renamed or moved, and lists
+// what changed.
+func diffLabel(n *treeNode, styles map[string]lipgloss.Style, display DisplayOptions) string {
+	style := styles[n.Change.Kind]
+	if n.Change.Kind == "renamed" || n.Change.Kind == "moved" {
+		style = styles["changed"]
+	}
+	if n.Spec.Line == 0 {
+		display.ShowFileLines = false
+	}
+	label := style.Render(n.Change.prefix()) + strings.TrimRight(specLabel(n.Spec, diffStyles(styles, n.Change.Kind), display), " ")
+	if details := n.Change.details(); len(details) > 0 {
+		label += style.Render(" — " + strings.Join(details, "; "))
+	}
+	return label
+}
+
+func markdownDiffLabel(n *treeNode, display DisplayOptions) string {
+	if n.Spec.Line == 0 {
+		display.ShowFileLines = false
+	}
+	change := n.Change
+	label := markdownSpecLabel(n.Spec, display)
+	prefix := "`" + diffMarkers[change.Kind] + "` "
+	if change.Kind == "renamed" || change.Kind == "moved" {
+		prefix = "**" + markdownEscaper.Replace(strings.TrimSpace(change.prefix())) + "** "
+	}
+	label = prefix + label
+	if details := change.details(); len(details) > 0 {
+		label += " — _" + markdownEscaper.Replace(strings.Join(details, "; ")) + "_"
+	}
+	return label
+}
+
+// prefix introduces the spec's label, e.g. "+ " or "renamed: old title → ".
+func (c *specChange) prefix() string {
+	switch c.Kind {
+	case "renamed":
+		return "renamed: " + c.Old.Title + " → "
+	case "moved":
+		return "moved: "
+	}
+	return diffMarkers[c.Kind] + " "
+}
+
+// details describes the changes not told by the prefix.
+func (c *specChange) details() []string {
+	var details []string
+	for _, field := range c.Changes {
+		if field.Field != "title" {
+			details = append(details, field.String())
+		}
+	}
+	return details
+}
+
+// diffStyles renders the titles of added and removed specs in their diff
+// style.
+func diffStyles(styles map[string]lipgloss.Style, kind string) map[string]lipgloss.Style {
+	style, ok := styles[kind]
+	if !ok || kind == "changed" {
+		return styles
+	}
+	marked := make(map[string]lipgloss.Style, len(styles))
+	for name, s := range styles {
+		marked[name] = s
+	}
+	marked["test"] = style
+	return marked
+}
+
+func renderDiffView(old, new []inventoryEntry, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) string {
+	changes := diffInventories(old, new)
+	summary := strings.Join(diffSummary(old, new, changes), "\n")
+	return renderTree(buildDiffTree(changes), styles, display, emojis, summary)
+}
+
+func renderMarkdownDiffView(old, new []inventoryEntry, display DisplayOptions) string {
+	changes := diffInventories(old, new)
+	summary := diffSummary(old, new, changes)
+	var b strings.Builder
+	b.WriteString("**" + summary[0] + "**\n")
+	if len(summary) > 1 {
+		b.WriteString("\n```\n" + strings.Join(summary[1:], "\n") + "\n```\n")
+	}
+	writeMarkdownFiles(&b, buildDiffTree(changes), display)
+	return b.String()
+}
+
+func renderJSONDiffView(old, new []inventoryEntry) (string, error) {
+	changes := diffInventories(old, new)
+	out := jsonTree(buildDiffTree(changes))
+	out.Summary = diffSummary(old, new, changes)
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// loadDiffInventories reads the reports compared by `pwtree diff`: two
+// files, a file and the current list, or the list of a git ref and either.
+func loadDiffInventories(args []string) ([]inventoryEntry, []inventoryEntry, error) {
+	var reports []PlaywrightJSON
+	if diffAgainst != "" {
+		listArgs := playwrightListArgs(projects, false, false, configFile, grepPattern, grepInvert)
+		raw, err := listAtRef(diffAgainst, listArgs)
+		if err != nil {
+			return nil, nil, err
+		}
+		report, err := loadPlaywrightJSON(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing the list of %s: %v", diffAgainst, err)
+		}
+		reports = append(reports, report)
+	}
+	for _, name := range args {
+		report, err := loadReports([]string{name})
+		if err != nil {
+			return nil, nil, err
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) == 1 {
+		current, err := loadCurrentReport()
+		if err != nil {
+			return nil, nil, err
+		}
+		reports = append(reports, current)
+	}
+	return inventory(filterReport(reports[0])), inventory(filterReport(reports[1])), nil
+}
+
+func runDiff(args []string, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) {
+	files := len(args)
+	if diffAgainst != "" {
+		files++
+	}
+	if files < 1 || files > 2 {
+		fmt.Println("Usage: pwtree diff <old.json> [new.json] | pwtree diff --against <git ref> [new.json]")
+		os.Exit(1)
+	}
+	old, new, err := loadDiffInventories(args)
+	if err != nil {
+		fmt.Printf("Error loading reports: %v\n", err)
+		os.Exit(1)
+	}
+	printDiff(old, new, styles, display, emojis)
+}
+
+func printDiff(old, new []inventoryEntry, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) {
+	switch outputFormat {
+	case "json":
+		out, err := renderJSONDiffView(old, new)
+		if err != nil {
+			fmt.Printf("Error encoding JSON output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	case "markdown":
+		fmt.Print(renderMarkdownDiffView(old, new, display))
+	default:
+		fmt.Println(renderDiffView(old, new, styles, display, emojis))
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func diffFixtures() (PlaywrightJSON, PlaywrightJSON) {
+	chromium := []TestInstance{{ProjectName: "chromium"}}
+	both := []TestInstance{{ProjectName: "chromium"}, {ProjectName: "webkit"}}
+	old := PlaywrightJSON{Suites: []Suite{
+		{Title: "cart.spec.ts", File: "cart.spec.ts", Suites: []Suite{
+			{Title: "Cart", File: "cart.spec.ts", Line: 3, Specs: []Spec{
+				{Title: "adds an item", File: "cart.spec.ts", Line: 4, Tags: []string{"@smoke"}, Tests: both},
+				{Title: "removes an item", File: "cart.spec.ts", Line: 9, Tests: both},
+				{Title: "empties the cart", File: "cart.spec.ts", Line: 14, Tests: chromium},
+			}},
+		}},
+		{Title: "legacy.spec.ts", File: "legacy.spec.ts", Specs: []Spec{
+			{Title: "old checkout", File: "legacy.spec.ts", Line: 1, Tests: both},
+		}},
+	}}
+	new := PlaywrightJSON{Suites: []Suite{
+		{Title: "cart.spec.ts", File: "cart.spec.ts", Suites: []Suite{
+			{Title: "Cart", File: "cart.spec.ts", Line: 3, Specs: []Spec{
+				{Title: "adds an item", File: "cart.spec.ts", Line: 4, Tags: []string{"@cart"}, Tests: chromium},
+				{Title: "removes an item", File: "cart.spec.ts", Line: 12, Tests: []TestInstance{
+					{ProjectName: "chromium", Annotations: []Annotation{{Type: "skip"}}},
+					{ProjectName: "webkit", Annotations: []Annotation{{Type: "skip"}}},
+				}},
+				{Title: "empties the cart", File: "cart.spec.ts", Line: 17, Tests: chromium},
+				{Title: "applies a coupon", File: "cart.spec.ts", Line: 22, Tests: both},
+			}},
+		}},
+	}}
+	return old, new
+}
+
+func TestInventory(t *testing.T) {
+	old, _ := diffFixtures()
+	entries := inventory(old)
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 specs, got %d", len(entries))
+	}
+	if got := entries[0]; got.Key != "cart.spec.ts › Cart › adds an item" || !reflect.DeepEqual(got.Projects, []string{"chromium", "webkit"}) {
+		t.Errorf("Unexpected entry %+v", got)
+	}
+	if got := entries[3].Key; got != "legacy.spec.ts › old checkout" {
+		t.Errorf("Expected top-level specs to have no describes, got %q", got)
+	}
+
+	duplicate := PlaywrightJSON{Suites: []Suite{{Title: "a.spec.ts", File: "a.spec.ts", Specs: []Spec{
+		{Title: "same", File: "a.spec.ts", Line: 1},
+		{Title: "same", File: "a.spec.ts", Line: 5},
+	}}}}
+	if got := inventory(duplicate)[1].Key; got != "a.spec.ts › same #2" {
+		t.Errorf("Expected duplicate titles to be numbered, got %q", got)
+	}
+}
+
+func TestDiffInventories(t *testing.T) {
+	old, new := diffFixtures()
+	changes := diffInventories(inventory(old), inventory(new))
+
+	var got []string
+	for _, c := range changes {
+		var fields []string
+		for _, f := range c.Changes {
+			fields = append(fields, f.String())
+		}
+		got = append(got, c.Kind+" "+c.entry().Title+" "+strings.Join(fields, "; "))
+	}
+	expected := []string{
+		"changed adds an item tags +@cart -@smoke; projects -webkit",
+		"changed removes an item annotations +skip; line 9 → 12",
+		"changed empties the cart line 14 → 17",
+		"added applies a coupon ",
+		"removed old checkout ",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected changes\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	summary := strings.Join(diffSummary(inventory(old), inventory(new), changes), "\n")
+	for _, line := range []string{
+		"Tests: 7 → 6 (-1)",
+		"Added: 1 spec (2 tests)",
+		"Removed: 1 spec (2 tests)",
+		"Changed: 3 specs",
+		"  tags changed: 1",
+		"  projects changed: 1 (+0/-1 tests)",
+		"  annotations added: 1 (skip 1)",
+		"  line changed: 2",
+	} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Expected summary to contain %q, got\n%s", line, summary)
+		}
+	}
+
+	if got := diffSummary(inventory(old), inventory(old), nil); !reflect.DeepEqual(got, []string{"Tests: 7 → 7 (+0)", "No differences"}) {
+		t.Errorf("Unexpected summary of identical inventories: %v", got)
+	}
+}
+
+func TestDiffInventories_RenamesAndMoves(t *testing.T) {
+	spec := func(id, title, file string, line int) Spec {
+		return Spec{ID: id, Title: title, File: file, Line: line, Tests: []TestInstance{{ProjectName: "chromium"}}}
+	}
+	old := PlaywrightJSON{Suites: []Suite{
+		{Title: "a.spec.ts", File: "a.spec.ts", Suites: []Suite{
+			{Title: "Login", File: "a.spec.ts", Line: 4, Specs: []Spec{
+				spec("", "logs in with password", "a.spec.ts", 5),
+				spec("", "logs out", "a.spec.ts", 10),
+				spec("", "shows banner", "a.spec.ts", 20),
+				spec("", "deletes account", "a.spec.ts", 40),
+			}},
+		}},
+		{Title: "b.spec.ts", File: "b.spec.ts", Specs: []Spec{spec("m1", "opens menu", "b.spec.ts", 3)}},
+	}}
+	new := PlaywrightJSON{Suites: []Suite{
+		{Title: "a.spec.ts", File: "a.spec.ts", Suites: []Suite{
+			{Title: "Login", File: "a.spec.ts", Line: 4, Specs: []Spec{
+				spec("", "logs in with a password", "a.spec.ts", 5),
+				spec("", "creates user", "a.spec.ts", 42),
+			}},
+			{Title: "Header", File: "a.spec.ts", Line: 29, Specs: []Spec{spec("", "shows banner", "a.spec.ts", 30)}},
+		}},
+		{Title: "c.spec.ts", File: "c.spec.ts", Specs: []Spec{spec("", "logs out", "c.spec.ts", 2)}},
+		{Title: "d.spec.ts", File: "d.spec.ts", Specs: []Spec{spec("m1", "toggles navigation", "d.spec.ts", 8)}},
+	}}
+
+	var got []string
+	for _, c := range diffInventories(inventory(old), inventory(new)) {
+		var fields []string
+		for _, f := range c.Changes {
+			fields = append(fields, f.String())
+		}
+		got = append(got, c.Kind+" "+c.entry().Title+" "+strings.Join(fields, "; "))
+	}
+	expected := []string{
+		"renamed logs in with a password renamed from logs in with password",
+		"added creates user ",
+		"moved shows banner from a.spec.ts › Login",
+		"moved logs out from a.spec.ts › Login",
+		"renamed toggles navigation renamed from opens menu; from b.spec.ts",
+		"removed deletes account ",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected changes\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	output := renderDiffView(inventory(old), inventory(new), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	for _, line := range []string{
+		"renamed: logs in with password → logs in with a password",
+		"moved: logs out — from a.spec.ts › Login",
+		"Renamed: 2 specs",
+		"Moved: 2 specs",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got\n%s", line, output)
+		}
+	}
+}
+
+func TestBuildDiffTree_DuplicateTitles(t *testing.T) {
+	// Specs listed once per project are one spec; specs sharing a title at
+	// different lines are two.
+	old := PlaywrightJSON{Suites: []Suite{{Title: "a.spec.ts", File: "a.spec.ts", Specs: []Spec{
+		{ID: "1", Title: "has title", File: "a.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium"}}},
+		{ID: "2", Title: "has title", File: "a.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "webkit"}}},
+		{Title: "same", File: "a.spec.ts", Line: 5, Tests: []TestInstance{{ProjectName: "chromium"}}},
+		{Title: "same", File: "a.spec.ts", Line: 9, Tests: []TestInstance{{ProjectName: "chromium"}}},
+	}}}}
+	new := PlaywrightJSON{Suites: []Suite{{Title: "a.spec.ts", File: "a.spec.ts", Specs: []Spec{
+		{ID: "1", Title: "has title", File: "a.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium"}}},
+	}}}}
+
+	entries := inventory(old)
+	if len(entries) != 3 || !reflect.DeepEqual(entries[0].Projects, []string{"chromium", "webkit"}) {
+		t.Fatalf("Expected per-project repeats to be merged, got %+v", entries)
+	}
+
+	var marks []string
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.Kind == specNode {
+			if n.Change == nil {
+				t.Errorf("Expected %s at line %d to be marked", n.Spec.Title, n.Spec.Line)
+				return
+			}
+			marks = append(marks, fmt.Sprintf("%s %s:%d", n.Change.Kind, n.Spec.Title, n.Spec.Line))
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(buildDiffTree(diffInventories(entries, inventory(new))))
+	expected := []string{"changed has title:3", "removed same:5", "removed same:9"}
+	if !reflect.DeepEqual(marks, expected) {
+		t.Errorf("Expected %v, got %v", expected, marks)
+	}
+}
+
+func TestDiffReport_ProjectAnnotations(t *testing.T) {
+	report := func(tests ...TestInstance) PlaywrightJSON {
+		return PlaywrightJSON{Suites: []Suite{{Title: "a.spec.ts", File: "a.spec.ts", Specs: []Spec{
+			{Title: "works", File: "a.spec.ts", Line: 3, Tests: tests},
+		}}}}
+	}
+	old := report(TestInstance{ProjectName: "chromium"}, TestInstance{ProjectName: "webkit"})
+	new := report(TestInstance{ProjectName: "chromium"}, TestInstance{ProjectName: "webkit", Annotations: []Annotation{{Type: "skip"}}})
+
+	spec := diffReport(diffInventories(inventory(old), inventory(new))).Suites[0].Specs[0]
+	annotations := map[string][]Annotation{}
+	for _, test := range spec.Tests {
+		annotations[test.ProjectName] = test.Annotations
+	}
+	if len(annotations["chromium"]) != 0 || !reflect.DeepEqual(annotations["webkit"], []Annotation{{Type: "skip"}}) {
+		t.Errorf("Expected only webkit to be skipped, got %v", annotations)
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected float64
+	}{
+		{"logs in", "logs in", 1},
+		{"", "", 1},
+		{"abcd", "abce", 0.75},
+		{"kitten", "sitting", 1 - 3.0/7},
+	}
+	for _, c := range cases {
+		if got := titleSimilarity(c.a, c.b); got != c.expected {
+			t.Errorf("titleSimilarity(%q, %q) = %v, expected %v", c.a, c.b, got, c.expected)
+		}
+	}
+}
+
+func TestRenderDiffView(t *testing.T) {
+	old, new := diffFixtures()
+	output := renderDiffView(inventory(old), inventory(new), map[string]lipgloss.Style{}, DisplayOptions{ShowFileLines: true}, DisplayEmojis{})
+
+	for _, line := range []string{
+		"cart.spec.ts",
+		"Cart (cart.spec.ts:3)",
+		"~ adds an item (cart.spec.ts:4) — tags +@cart -@smoke; projects -webkit",
+		"+ applies a coupon (cart.spec.ts:22)",
+		"legacy.spec.ts",
+		"- old checkout (legacy.spec.ts:1)",
+		"Tests: 7 → 6 (-1)",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got\n%s", line, output)
+		}
+	}
+	if strings.Index(output, "empties the cart") > strings.Index(output, "applies a coupon") {
+		t.Errorf("Expected specs to be sorted by line, got\n%s", output)
+	}
+}
+
+func TestRenderJSONDiffView(t *testing.T) {
+	old, new := diffFixtures()
+	out, err := renderJSONDiffView(inventory(old), inventory(new))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree JSONTree
+	if err := json.Unmarshal([]byte(out), &tree); err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Summary) == 0 || tree.Summary[0] != "Tests: 7 → 6 (-1)" {
+		t.Errorf("Unexpected summary %v", tree.Summary)
+	}
+	removed := tree.Children[1].Children[0]
+	if removed.Change != "removed" || removed.Title != "old checkout" {
+		t.Errorf("Expected the removed spec to be marked, got %+v", removed)
+	}
+	changed := tree.Children[0].Children[0].Children[0]
+	if changed.Change != "changed" || !reflect.DeepEqual(changed.Changes, []string{"tags +@cart -@smoke", "projects -webkit"}) {
+		t.Errorf("Expected the changed spec to list its changes, got %+v", changed)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -53,17 +54,13 @@ func filterSuitesByFilter(suites []Suite, filterTerms []string) []Suite {
 }
 
 func matchesNegativeFilter(title, file string, tags []string, filters []string) bool {
+	target := &queryTarget{Title: title, File: file, Tags: tags}
 	for _, f := range filters {
-		if strings.HasPrefix(f, "-") {
-			term := strings.TrimPrefix(f, "-")
-			if strings.Contains(title, term) || strings.Contains(file, term) {
+		f = strings.TrimSpace(f)
+		if strings.HasPrefix(f, "-") && len(f) > 1 {
+			if filterTerm(strings.TrimPrefix(f, "-")).eval(target) {
 				return true
 			}
-			for _, tag := range tags {
-				if strings.Contains(tag, term) || strings.Contains("@"+tag, term) {
-					return true
-				}
-			}
 		}
 	}
 	return false
@@ -109,15 +106,12 @@ func matchesFilter(title, file string, tags []string, filters []string) bool {
 		}
 	}
 
+	target := &queryTarget{Title: title, File: file, Tags: tags}
+
 	for _, neg := range negative {
-		if strings.Contains(title, neg) || strings.Contains(file, neg) {
+		if filterTerm(neg).eval(target) {
 			return false
 		}
-		for _, tag := range tags {
-			if strings.Contains(tag, neg) || strings.Contains("@"+tag, neg) {
-				return false
-			}
-		}
 	}
 
 	if len(positive) == 0 {
@@ -125,19 +119,61 @@ func matchesFilter(title, file string, tags []string, filters []string) bool {
 	}
 
 	for _, pos := range positive {
-		if strings.Contains(title, pos) || strings.Contains(file, pos) {
+		if filterTerm(pos).eval(target) {
 			return true
 		}
-		for _, tag := range tags {
-			if strings.Contains(tag, pos) || strings.Contains("@"+tag, pos) {
-				return true
-			}
-		}
 	}
 
 	return false
 }
 
+// filterTermFields are the qualifiers accepted in --filter terms. Projects and
+// annotations have their own flags.
+var filterTermFields = []string{"title", "file", "tag"}
+
+var compiledFilterTerms = map[string]termExpr{}
+
+// parseFilterTerm compiles a single --filter term, which may be qualified
+// (file:tests/checkout/**), a /regular expression/flags or a plain substring.
+func parseFilterTerm(term string) (termExpr, error) {
+	for _, field := range filterTermFields {
+		if value, ok := strings.CutPrefix(term, field+":"); ok && value != "" {
+			return newTermExpr(field, value)
+		}
+	}
+	return newTermExpr("", term)
+}
+
+// filterTerm returns the compiled term, caching it since filters are matched
+// against every suite and spec. Invalid terms, reported earlier by
+// validateFilterTerms, match nothing.
+func filterTerm(term string) termExpr {
+	key := fmt.Sprintf("%t:%s", *ignoreCase, term)
+	if compiled, ok := compiledFilterTerms[key]; ok {
+		return compiled
+	}
+	compiled, err := parseFilterTerm(term)
+	if err != nil {
+		never := func(string) bool { return false }
+		compiled = termExpr{title: never, file: never, tag: never}
+	}
+	compiledFilterTerms[key] = compiled
+	return compiled
+}
+
+func validateFilterTerms(filters []string) error {
+	for _, f := range filters {
+		f = strings.TrimPrefix(strings.TrimSpace(f), "-")
+		if f == "" {
+			continue
+		}
+		if _, err := parseFilterTerm(f); err != nil {
+			return fmt.Errorf("%s: %v", f, err)
+		}
+	}
+	return nil
+}
+
 func filterSuitesByAnnotation(suites []Suite, showSkipped, showFixme, showFail bool) []Suite {
 	var filtered []Suite
 
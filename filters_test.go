@@ -288,3 +288,37 @@ func TestApplyNegativeFilterToSuites(t *testing.T) {
 		t.Errorf("Expected only 'should run' spec to remain, got %+v", specs)
 	}
 }
+
+func TestFilterSuitesByFilter_WholeTagsAndGlobs(t *testing.T) {
+	suites := []Suite{
+		{
+			Title: "tests/checkout/cart.spec.ts",
+			File:  "tests/checkout/cart.spec.ts",
+			Specs: []Spec{
+				{Title: "smoke test", File: "tests/checkout/cart.spec.ts", Tags: []string{"smoke"}},
+				{Title: "extended test", File: "tests/checkout/cart.spec.ts", Tags: []string{"smoke-extended"}},
+			},
+		},
+		{
+			Title: "tests/checkout-legacy/cart.spec.ts",
+			File:  "tests/checkout-legacy/cart.spec.ts",
+			Specs: []Spec{
+				{Title: "legacy test", File: "tests/checkout-legacy/cart.spec.ts", Tags: []string{"smoke"}},
+			},
+		},
+	}
+
+	filtered := filterSuitesByFilter(suites, []string{"@smoke"})
+	if len(filtered) != 2 || len(filtered[0].Specs) != 1 || filtered[0].Specs[0].Title != "smoke test" {
+		t.Errorf("Expected @smoke to match whole tags only, got %+v", filtered)
+	}
+
+	filtered = filterSuitesByFilter(suites, []string{"file:tests/checkout/**/*.spec.ts"})
+	if len(filtered) != 1 || filtered[0].File != "tests/checkout/cart.spec.ts" {
+		t.Errorf("Expected the glob to exclude checkout-legacy, got %+v", filtered)
+	}
+
+	if err := validateFilterTerms([]string{"-/[/"}); err == nil {
+		t.Error("Expected an invalid regular expression to be reported")
+	}
+}
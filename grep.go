@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var grepLiteralPattern = regexp.MustCompile(`^/(.*)/([gi]*)$`)
+
+// compileGrep mirrors Playwright's handling of --grep: /pattern/flags is used
+// as written, anything else is a case-insensitive pattern.
+func compileGrep(pattern string) (*regexp.Regexp, error) {
+	flags := "i"
+	if m := grepLiteralPattern.FindStringSubmatch(pattern); m != nil {
+		pattern = m[1]
+		flags = strings.ReplaceAll(m[2], "g", "")
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern: %v", err)
+	}
+	return re, nil
+}
+
+// grepTitle builds the string Playwright matches --grep against: the project
+// name, file, describe titles, test title and tags joined with spaces.
+func grepTitle(project string, path []string, spec Spec) string {
+	parts := []string{project}
+	parts = append(parts, path...)
+	parts = append(parts, spec.Title)
+	for _, tag := range spec.Tags {
+		parts = append(parts, normalizeTag(tag))
+	}
+	return strings.Join(parts, " ")
+}
+
+func filterSuitesByGrep(suites []Suite, grep, grepInvert *regexp.Regexp) []Suite {
+	return filterSuitesByGrepWithin(suites, grep, grepInvert, nil)
+}
+
+func filterSuitesByGrepWithin(suites []Suite, grep, grepInvert *regexp.Regexp, path []string) []Suite {
+	var filtered []Suite
+
+	for _, suite := range suites {
+		suitePath := path
+		if suite.Title != "" {
+			suitePath = append(append([]string{}, path...), suite.Title)
+		}
+
+		suite.Suites = filterSuitesByGrepWithin(suite.Suites, grep, grepInvert, suitePath)
+
+		var newSpecs []Spec
+		for _, spec := range suite.Specs {
+			var filteredTests []TestInstance
+			for _, test := range spec.Tests {
+				title := grepTitle(test.ProjectName, suitePath, spec)
+				if grep != nil && !grep.MatchString(title) {
+					continue
+				}
+				if grepInvert != nil && grepInvert.MatchString(title) {
+					continue
+				}
+				filteredTests = append(filteredTests, test)
+			}
+			if len(filteredTests) > 0 {
+				spec.Tests = filteredTests
+				newSpecs = append(newSpecs, spec)
+			}
+		}
+		suite.Specs = newSpecs
+
+		if len(suite.Suites) > 0 || len(suite.Specs) > 0 {
+			filtered = append(filtered, suite)
+		}
+	}
+
+	return filtered
+}
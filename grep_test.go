@@ -0,0 +1,82 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFilterSuitesByGrep(t *testing.T) {
+	suites := []Suite{
+		{
+			Title: "checkout.spec.ts",
+			File:  "checkout.spec.ts",
+			Suites: []Suite{
+				{
+					Title: "Editing",
+					File:  "checkout.spec.ts",
+					Specs: []Spec{
+						{
+							Title: "saves on blur",
+							File:  "checkout.spec.ts",
+							Tags:  []string{"smoke"},
+							Tests: []TestInstance{{ProjectName: "chromium"}, {ProjectName: "webkit"}},
+						},
+						{
+							Title: "cancels on escape",
+							File:  "checkout.spec.ts",
+							Tests: []TestInstance{{ProjectName: "chromium"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	countTests := func(suites []Suite) int {
+		total := 0
+		var walk func(suites []Suite)
+		walk = func(suites []Suite) {
+			for _, suite := range suites {
+				for _, spec := range suite.Specs {
+					total += len(spec.Tests)
+				}
+				walk(suite.Suites)
+			}
+		}
+		walk(suites)
+		return total
+	}
+
+	cases := []struct {
+		grep, invert string
+		expected     int
+	}{
+		// Describe titles, file names and tags take part in the match.
+		{"editing", "", 3},
+		{"checkout.spec.ts Editing saves", "", 2},
+		{"@smoke", "", 2},
+		// The project name is part of the title path.
+		{"^webkit ", "", 1},
+		{"", "@smoke", 1},
+		{"/Editing/", "chromium", 1},
+		{"/editing/", "", 0},
+	}
+	for _, c := range cases {
+		var grep, invert *regexp.Regexp
+		if c.grep != "" {
+			grep, _ = compileGrep(c.grep)
+		}
+		if c.invert != "" {
+			invert, _ = compileGrep(c.invert)
+		}
+		if got := countTests(filterSuitesByGrep(suites, grep, invert)); got != c.expected {
+			t.Errorf("--grep %q --grep-invert %q: expected %d tests, got %d", c.grep, c.invert, c.expected, got)
+		}
+	}
+}
+
+func TestCompileGrep_Error(t *testing.T) {
+	if _, err := compileGrep("(?=lookahead)"); err == nil {
+		t.Error("Expected unsupported patterns to be reported")
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultHeatmapGradient runs from green for the fastest nodes to red for the
+// slowest.
+var defaultHeatmapGradient = []string{"#5fd75f", "#ffd75f", "#ff5f5f"}
+
+// heatStyleNames are the styles recolored on heat mapped nodes.
+var heatStyleNames = []string{
+	"dir", "file", "suite", "test", "passed", "failed", "flaky", "skipped", "fixme", "fail",
+}
+
+// heatmapGradient is the configured gradient, set by loadStyleConfig.
+var heatmapGradient = defaultHeatmapGradient
+
+type rgb struct{ r, g, b float64 }
+
+// parseRGB reads hex and ANSI colors, as accepted by the styles config.
+func parseRGB(color string) (rgb, bool) {
+	hex := strings.TrimPrefix(cssColor(color), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return rgb{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgb{}, false
+	}
+	return rgb{float64(v >> 16 & 0xff), float64(v >> 8 & 0xff), float64(v & 0xff)}, true
+}
+
+func (c rgb) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", int(math.Round(c.r)), int(math.Round(c.g)), int(math.Round(c.b)))
+}
+
+// gradientColor interpolates the gradient at t, between 0 and 1.
+func gradientColor(gradient []string, t float64) string {
+	var stops []rgb
+	for _, color := range gradient {
+		if c, ok := parseRGB(color); ok {
+			stops = append(stops, c)
+		}
+	}
+	if len(stops) == 0 {
+		return ""
+	}
+	if len(stops) == 1 {
+		return stops[0].hex()
+	}
+
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(stops)-1)
+	i := int(pos)
+	if i == len(stops)-1 {
+		i--
+	}
+	f := pos - float64(i)
+	a, b := stops[i], stops[i+1]
+	mix := func(x, y float64) float64 { return x + (y-x)*f }
+	return rgb{mix(a.r, b.r), mix(a.g, b.g), mix(a.b, b.b)}.hex()
+}
+
+// rollUpDurations sets the duration of each spec to the time its projects
+// took, retries included, and of each other node to the sum of its children.
+func rollUpDurations(n *treeNode) time.Duration {
+	switch n.Kind {
+	case resultNode, detailNode:
+		return 0
+	case specNode:
+		n.Duration = 0
+		for project := range n.Spec.Projects {
+			if outcome, ok := n.Spec.Outcomes[project]; ok {
+				n.Duration += outcome.Duration
+			}
+		}
+		return n.Duration
+	}
+
+	n.Duration = 0
+	for _, child := range n.Children {
+		n.Duration += rollUpDurations(child)
+	}
+	return n.Duration
+}
+
+// applyHeatmap colors every node by its duration relative to the slowest node
+// of the same kind, so files are compared with files and suites with suites.
+func applyHeatmap(root *treeNode, gradient []string) {
+	slowest := map[nodeKind]time.Duration{}
+	var measure func(n *treeNode)
+	measure = func(n *treeNode) {
+		if n.Kind == resultNode || n.Kind == detailNode {
+			return
+		}
+		slowest[n.Kind] = max(slowest[n.Kind], n.Duration)
+		for _, child := range n.Children {
+			measure(child)
+		}
+	}
+	measure(root)
+
+	var color func(n *treeNode)
+	color = func(n *treeNode) {
+		if n.Kind == resultNode || n.Kind == detailNode {
+			return
+		}
+		if n.Kind != rootNode && slowest[n.Kind] > 0 {
+			n.Heat = gradientColor(gradient, float64(n.Duration)/float64(slowest[n.Kind]))
+		}
+		for _, child := range n.Children {
+			color(child)
+		}
+	}
+	color(root)
+}
+
+// heatStyles recolors the title styles of a node with its heat color.
+func heatStyles(styles map[string]lipgloss.Style, heat string) map[string]lipgloss.Style {
+	if *ciMode {
+		return styles
+	}
+	heated := make(map[string]lipgloss.Style, len(styles))
+	for name, style := range styles {
+		heated[name] = style
+	}
+	for _, name := range heatStyleNames {
+		heated[name] = styles[name].Foreground(lipgloss.Color(heat))
+	}
+	return heated
+}
+
+type slowTest struct {
+	Path     []string
+	Project  string
+	Status   string
+	File     string
+	Line     int
+	Duration time.Duration
+}
+
+// slowestTests lists every run test with its path of file and describe
+// titles, slowest first.
+func slowestTests(root *treeNode) []slowTest {
+	var tests []slowTest
+	seen := map[string]bool{}
+
+	var walk func(n *treeNode, path []string)
+	walk = func(n *treeNode, path []string) {
+		switch n.Kind {
+		case fileNode:
+			path = append(path[:len(path):len(path)], n.File)
+		case suiteNode:
+			path = append(path[:len(path):len(path)], n.Title)
+		case specNode:
+			for _, project := range sortedKeys(n.Spec.Projects) {
+				outcome, ok := n.Spec.Outcomes[project]
+				key := fmt.Sprintf("%s:%d:%s|%s", n.Spec.File, n.Spec.Line, n.Spec.Title, project)
+				if !ok || seen[key] {
+					continue
+				}
+				seen[key] = true
+				tests = append(tests, slowTest{
+					Path:     append(path[:len(path):len(path)], n.Title),
+					Project:  project,
+					Status:   outcome.Status,
+					File:     n.Spec.File,
+					Line:     n.Spec.Line,
+					Duration: outcome.Duration,
+				})
+			}
+			return
+		}
+		for _, child := range n.Children {
+			walk(child, path)
+		}
+	}
+	walk(root, nil)
+
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].Duration > tests[j].Duration
+	})
+	return tests
+}
+
+func buildSlowestView(root *treeNode, top int, styles map[string]lipgloss.Style, display DisplayOptions, gradient []string) string {
+	tests := slowestTests(root)
+	if len(tests) == 0 {
+		return "No test durations found. 'pwtree slowest' needs the JSON report of a test run.\n"
+	}
+
+	var total time.Duration
+	for _, test := range tests {
+		total += test.Duration
+	}
+	shown := tests[:min(top, len(tests))]
+
+	var b strings.Builder
+	width := len(strconv.Itoa(len(shown)))
+	for i, test := range shown {
+		duration := fmt.Sprintf("%7s", formatDuration(test.Duration))
+		if !*ciMode && tests[0].Duration > 0 {
+			duration = lipgloss.NewStyle().Foreground(lipgloss.Color(
+				gradientColor(gradient, float64(test.Duration)/float64(tests[0].Duration)))).Render(duration)
+		}
+		line := fmt.Sprintf("%*d. %s  %s", width, i+1, duration, styles["test"].Render(strings.Join(test.Path, " › ")))
+		if display.ShowProjects {
+			line += styles["project"].Render(" (" + test.Project + ")")
+		}
+		if test.Status != "passed" {
+			line += " " + styles[outcomeStyleName(test.Status)].Render("["+test.Status+"]")
+		}
+		if display.ShowFileLines {
+			line += " " + styles["fileLine"].Render(fmt.Sprintf("(%s:%d)", test.File, test.Line))
+		}
+		b.WriteString(line + "\n")
+	}
+
+	var shownTotal time.Duration
+	for _, test := range shown {
+		shownTotal += test.Duration
+	}
+	counter := fmt.Sprintf("Slowest %d of %d test%s: %s of %s in total",
+		len(shown), len(tests), pluralize(len(tests)), formatDuration(shownTotal), formatDuration(total))
+	return "\n" + b.String() + "\n" + styles["counter"].Render(counter) + "\n"
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestGradientColor(t *testing.T) {
+	gradient := []string{"#000000", "#ff0000", "15"}
+	cases := map[float64]string{
+		0:    "#000000",
+		0.25: "#800000",
+		0.5:  "#ff0000",
+		1:    "#ffffff",
+		2:    "#ffffff",
+	}
+	for at, expected := range cases {
+		if got := gradientColor(gradient, at); got != expected {
+			t.Errorf("At %v: expected %s, got %s", at, expected, got)
+		}
+	}
+	if got := gradientColor([]string{"nope", "#f00"}, 0.5); got != "#ff0000" {
+		t.Errorf("Expected the only valid stop, got %s", got)
+	}
+	if got := gradientColor([]string{"nope"}, 0.5); got != "" {
+		t.Errorf("Expected no color for an invalid gradient, got %s", got)
+	}
+}
+
+func TestApplyHeatmap(t *testing.T) {
+	original := *heatmap
+	defer func() { *heatmap = original }()
+	*heatmap = true
+
+	root := buildNodeTree(loadResultsFixture(t))
+	checkout, login := root.Children[0], root.Children[1]
+
+	if checkout.Duration != 46260*time.Millisecond || login.Duration != 7590*time.Millisecond {
+		t.Errorf("Expected rolled up file durations, got %v and %v", checkout.Duration, login.Duration)
+	}
+	if checkout.Heat != defaultHeatmapGradient[2] {
+		t.Errorf("Expected the slowest file to get the last color, got %s", checkout.Heat)
+	}
+	if payment := checkout.Children[1]; payment.Heat != defaultHeatmapGradient[0] {
+		t.Errorf("Expected a suite without duration to get the first color, got %s", payment.Heat)
+	}
+
+	output := renderTreeView(root, map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	if !strings.Contains(output, "checkout.spec.ts 46.3s") || !strings.Contains(output, "logs out [passed] 1.3s") {
+		t.Errorf("Expected durations after labels, got:\n%s", output)
+	}
+}
+
+func TestSlowestTests(t *testing.T) {
+	tests := slowestTests(buildNodeTree(loadResultsFixture(t)))
+
+	if len(tests) != 10 {
+		t.Fatalf("Expected 10 tests, got %d", len(tests))
+	}
+	slowest := tests[0]
+	if !reflect.DeepEqual(slowest.Path, []string{"checkout.spec.ts", "Cart", "applies a coupon"}) ||
+		slowest.Project != "chromium" || slowest.Duration != 30*time.Second {
+		t.Errorf("Unexpected slowest test %+v", slowest)
+	}
+
+	output := buildSlowestView(buildNodeTree(loadResultsFixture(t)), 3, map[string]lipgloss.Style{}, DisplayOptions{ShowProjects: true}, defaultHeatmapGradient)
+	for _, want := range []string{
+		"1.   30.0s  checkout.spec.ts › Cart › applies a coupon (chromium) [timedOut]",
+		"3.    5.3s  login.spec.ts › logs in (chromium) [flaky]",
+		"Slowest 3 of 10 tests: 47.5s of 53.9s in total",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pathsFlag collects repeated path flags. Unlike multiFlag it does not split
+// on spaces, so paths may contain them.
+type pathsFlag []string
+
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// expandPaths resolves glob patterns, such as reports/*.json, to the files
+// they match. Plain paths, existing files such as reports/[shard-1].json,
+// and "-" are kept as is.
+func expandPaths(patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if pattern == "-" || !isGlob(pattern) {
+			paths = append(paths, pattern)
+			continue
+		}
+		if _, err := os.Stat(pattern); err == nil {
+			paths = append(paths, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// loadReports reads and merges the reports at the given paths or patterns.
+func loadReports(patterns []string) (PlaywrightJSON, error) {
+	paths, err := expandPaths(patterns)
+	if err != nil {
+		return PlaywrightJSON{}, err
+	}
+
+	var reports []PlaywrightJSON
+	for _, name := range paths {
+		raw, err := readJSONData(name)
+		if err != nil {
+			return PlaywrightJSON{}, err
+		}
+		report, err := loadPlaywrightJSON(raw)
+		if err != nil {
+			return PlaywrightJSON{}, fmt.Errorf("parsing %s: %v", name, err)
+		}
+		reports = append(reports, report)
+	}
+
+	if len(reports) == 1 {
+		return reports[0], nil
+	}
+	return mergeReports(reports), nil
+}
+
+// readJSONData reads a Playwright JSON report from a file, or from stdin when
+// the path is "-". Gzip files and zip archives (such as downloaded CI
+// artifacts) are unpacked, detected by their content rather than extension.
+func readJSONData(name string) ([]byte, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return readGzip(data)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readZip(data)
+	}
+	return data, nil
+}
+
+func readGzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// readZip returns the archive's report.json, or its first JSON file if there
+// is none.
+func readZip(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var report *zip.File
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		if path.Base(f.Name) == "report.json" {
+			report = f
+			break
+		}
+		if report == nil {
+			report = f
+		}
+	}
+	if report == nil {
+		return nil, fmt.Errorf("no JSON file found in zip archive")
+	}
+
+	r, err := report.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		return readGzip(content)
+	}
+	return content, nil
+}
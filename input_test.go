@@ -0,0 +1,66 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const inputFixture = `{"suites":[{"title":"a.spec.ts","file":"a.spec.ts"}]}`
+
+func TestReadJSONData(t *testing.T) {
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(inputFixture))
+	w.Close()
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"artifacts/other.json":  `{}`,
+		"artifacts/report.json": inputFixture,
+		"artifacts/readme.txt":  "not json",
+	} {
+		f, _ := zw.Create(name)
+		f.Write([]byte(content))
+	}
+	zw.Close()
+
+	files := map[string][]byte{
+		"report.json":    []byte(inputFixture),
+		"report.json.gz": gz.Bytes(),
+		"artifact.zip":   archive.Bytes(),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		data, err := readJSONData(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if string(data) != inputFixture {
+			t.Errorf("%s: expected %s, got %s", name, inputFixture, data)
+		}
+	}
+}
+
+func TestReadJSONData_ZipWithoutJSON(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, _ := zw.Create("readme.txt")
+	f.Write([]byte("not json"))
+	zw.Close()
+
+	path := filepath.Join(t.TempDir(), "artifact.zip")
+	os.WriteFile(path, archive.Bytes(), 0644)
+	if _, err := readJSONData(path); err == nil {
+		t.Error("Expected an error for a zip without JSON files")
+	}
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type treeRow struct {
+	node  *treeNode
+	depth int
+}
+
+type interactiveModel struct {
+	root      *treeNode
+	parents   map[*treeNode]*treeNode
+	collapsed map[*treeNode]bool
+	selected  map[*treeNode]bool
+	visible   map[*treeNode]bool
+	rows      []treeRow
+	cursor    int
+	offset    int
+	height    int
+	searching bool
+	query     string
+	run       []*treeNode
+	styles    map[string]lipgloss.Style
+	display   DisplayOptions
+	emojis    DisplayEmojis
+}
+
+func newInteractiveModel(root *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) *interactiveModel {
+	m := &interactiveModel{
+		root:      root,
+		parents:   map[*treeNode]*treeNode{},
+		collapsed: map[*treeNode]bool{},
+		selected:  map[*treeNode]bool{},
+		styles:    styles,
+		display:   display,
+		emojis:    emojis,
+	}
+	var index func(n *treeNode)
+	index = func(n *treeNode) {
+		for _, child := range n.Children {
+			m.parents[child] = n
+			index(child)
+		}
+	}
+	index(root)
+	m.refresh()
+	return m
+}
+
+// runInteractive browses the tree until the user quits, returning the nodes
+// they asked to run, if any.
+func runInteractive(root *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) ([]*treeNode, error) {
+	m := newInteractiveModel(root, styles, display, emojis)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		return nil, err
+	}
+	return m.run, nil
+}
+
+func (m *interactiveModel) Init() tea.Cmd {
+	return nil
+}
+
+// refresh recomputes the search matches and the flattened list of rows
+// that are currently on screen, keeping the cursor on the same node.
+func (m *interactiveModel) refresh() {
+	var current *treeNode
+	if m.cursor < len(m.rows) {
+		current = m.rows[m.cursor].node
+	}
+
+	m.visible = map[*treeNode]bool{}
+	query := strings.ToLower(m.query)
+	var mark func(n *treeNode, ancestorMatched bool) bool
+	mark = func(n *treeNode, ancestorMatched bool) bool {
+		matched := ancestorMatched || query == "" || strings.Contains(strings.ToLower(n.Title), query)
+		show := matched
+		for _, child := range n.Children {
+			if mark(child, matched) {
+				show = true
+			}
+		}
+		if show {
+			m.visible[n] = true
+		}
+		return show
+	}
+	mark(m.root, false)
+
+	m.rows = nil
+	var flatten func(n *treeNode, depth int)
+	flatten = func(n *treeNode, depth int) {
+		for _, child := range n.Children {
+			if !m.visible[child] {
+				continue
+			}
+			m.rows = append(m.rows, treeRow{node: child, depth: depth})
+			if m.query != "" || !m.collapsed[child] {
+				flatten(child, depth+1)
+			}
+		}
+	}
+	flatten(m.root, 0)
+
+	m.cursor = 0
+	for i, row := range m.rows {
+		if row.node == current {
+			m.cursor = i
+			break
+		}
+	}
+	m.scroll()
+}
+
+func (m *interactiveModel) scroll() {
+	height := m.pageHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
+
+func (m *interactiveModel) pageHeight() int {
+	// Leave room for the title, the counter and the key help.
+	if m.height <= 4 {
+		return 20
+	}
+	return m.height - 4
+}
+
+func (m *interactiveModel) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.scroll()
+}
+
+func (m *interactiveModel) setAllCollapsed(collapsed bool) {
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		for _, child := range n.Children {
+			if len(child.Children) > 0 {
+				m.collapsed[child] = collapsed
+			}
+			walk(child)
+		}
+	}
+	walk(m.root)
+	m.refresh()
+}
+
+func (m *interactiveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+func (m *interactiveModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.query = ""
+	case tea.KeyBackspace:
+		if len(m.query) > 0 {
+			runes := []rune(m.query)
+			m.query = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.query += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	m.refresh()
+	return m, nil
+}
+
+func (m *interactiveModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "pgup":
+		m.moveCursor(-m.pageHeight())
+	case "pgdown":
+		m.moveCursor(m.pageHeight())
+	case "home", "g":
+		m.moveCursor(-len(m.rows))
+	case "end", "G":
+		m.moveCursor(len(m.rows))
+	case "right", "l":
+		if node := m.currentNode(); node != nil && len(node.Children) > 0 {
+			if m.collapsed[node] {
+				m.collapsed[node] = false
+				m.refresh()
+			} else {
+				m.moveCursor(1)
+			}
+		}
+	case "left", "h":
+		if node := m.currentNode(); node != nil {
+			if len(node.Children) > 0 && !m.collapsed[node] && m.query == "" {
+				m.collapsed[node] = true
+				m.refresh()
+			} else if parent := m.parents[node]; parent != nil && parent != m.root {
+				for i, row := range m.rows {
+					if row.node == parent {
+						m.cursor = i
+						break
+					}
+				}
+				m.scroll()
+			}
+		}
+	case "enter", "tab":
+		if node := m.currentNode(); node != nil && len(node.Children) > 0 {
+			m.collapsed[node] = !m.collapsed[node]
+			m.refresh()
+		}
+	case " ":
+		if node := m.currentNode(); node != nil {
+			m.selected[node] = !m.selected[node]
+			m.moveCursor(1)
+		}
+	case "r":
+		m.run = m.selectedNodes()
+		if len(m.run) == 0 {
+			if node := m.currentNode(); node != nil {
+				m.run = []*treeNode{node}
+			}
+		}
+		return m, tea.Quit
+	case "e":
+		m.setAllCollapsed(false)
+	case "c":
+		m.setAllCollapsed(true)
+	case "/":
+		m.searching = true
+	case "esc":
+		if m.query != "" {
+			m.query = ""
+			m.refresh()
+		}
+	}
+	return m, nil
+}
+
+func (m *interactiveModel) currentNode() *treeNode {
+	if m.cursor < len(m.rows) {
+		return m.rows[m.cursor].node
+	}
+	return nil
+}
+
+// selectedNodes returns the selected nodes in tree order.
+func (m *interactiveModel) selectedNodes() []*treeNode {
+	var nodes []*treeNode
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if m.selected[n] {
+			nodes = append(nodes, n)
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(m.root)
+	return nodes
+}
+
+// visibleCounter returns the same counter buildTreeView prints, limited to
+// the nodes that survive the current search.
+func (m *interactiveModel) visibleCounter() string {
+	var prune func(n *treeNode) *treeNode
+	prune = func(n *treeNode) *treeNode {
+		pruned := *n
+		pruned.Children = nil
+		for _, child := range n.Children {
+			if m.visible[child] {
+				pruned.Children = append(pruned.Children, prune(child))
+			}
+		}
+		return &pruned
+	}
+	return treeCounter(prune(m.root))
+}
+
+func (m *interactiveModel) View() string {
+	var b strings.Builder
+
+	title := strings.TrimSpace(m.emojis.Root + " Playwright-tree")
+	b.WriteString(m.styles["root"].Render(title) + "\n")
+
+	height := m.pageHeight()
+	for i := m.offset; i < len(m.rows) && i < m.offset+height; i++ {
+		row := m.rows[i]
+		cursor := "  "
+		if i == m.cursor {
+			cursor = m.styles["enumerator"].Render("❯") + " "
+		}
+		if m.selected[row.node] {
+			cursor = m.styles["enumerator"].Render("●") + " "
+			if i == m.cursor {
+				cursor = m.styles["enumerator"].Render("❯●")
+			}
+		}
+		marker := "  "
+		if len(row.node.Children) > 0 {
+			if m.collapsed[row.node] && m.query == "" {
+				marker = "▸ "
+			} else {
+				marker = "▾ "
+			}
+		}
+		// Multi-line details, such as error messages, show their first line.
+		label, _, _ := strings.Cut(nodeLabel(row.node, m.styles, m.display, m.emojis), "\n")
+		b.WriteString(cursor + strings.Repeat("  ", row.depth) + marker + label + "\n")
+	}
+	for i := len(m.rows) - m.offset; i < height; i++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.styles["counter"].Render(m.visibleCounter()) + "\n")
+	switch {
+	case m.searching:
+		b.WriteString("/" + m.query + "█")
+	case m.query != "":
+		b.WriteString("filter: " + m.query + " • esc clear • / edit • q quit")
+	default:
+		b.WriteString("↑/↓ move • ←/→ fold • enter toggle • e/c unfold/fold all • / search • space select • r run • q quit")
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestInteractiveModel_FoldAndSearch(t *testing.T) {
+	pwData := PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "a.spec.ts",
+				File:  "a.spec.ts",
+				Suites: []Suite{
+					{
+						Title: "Checkout",
+						File:  "a.spec.ts",
+						Line:  3,
+						Specs: []Spec{
+							{Title: "pays", File: "a.spec.ts", Line: 4, Tests: []TestInstance{{ProjectName: "chromium"}, {ProjectName: "webkit"}}},
+						},
+					},
+				},
+			},
+			{
+				Title: "b.spec.ts",
+				File:  "b.spec.ts",
+				Specs: []Spec{
+					{Title: "logs in", File: "b.spec.ts", Line: 2, Tests: []TestInstance{{ProjectName: "chromium"}}},
+				},
+			},
+		},
+	}
+
+	m := newInteractiveModel(buildNodeTree(pwData), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	if len(m.rows) != 5 {
+		t.Fatalf("Expected 5 rows fully expanded, got %d", len(m.rows))
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if len(m.rows) != 2 {
+		t.Errorf("Expected 2 file rows after fold all, got %d", len(m.rows))
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if len(m.rows) != 5 {
+		t.Errorf("Expected 5 rows after unfold all, got %d", len(m.rows))
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "check" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.rows) != 3 {
+		t.Errorf("Expected matching suite with its file and spec, got %d rows", len(m.rows))
+	}
+	if view := m.View(); !strings.Contains(view, "Total: 2 tests in 1 file") {
+		t.Errorf("Expected counter for the visible subtree\nGot:\n%s", view)
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LintConfig is the "lint" section of the configuration file, keyed by rule
+// name, e.g. {"rules": {"untagged": {"severity": "error"}}}.
+type LintConfig struct {
+	Rules map[string]LintRule `json:"rules,omitempty"`
+}
+
+// LintRule enables a rule as an "error" or "warning", or disables it with
+// "off". Max parameterizes max-nesting and max-title-length, Tags lists the
+// tags accepted by allowed-tags.
+type LintRule struct {
+	Severity string   `json:"severity,omitempty"`
+	Max      int      `json:"max,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// lintRuleNames are the rules of `pwtree lint`, in the order they are
+// documented.
+var lintRuleNames = []string{
+	"duplicate-title",
+	"untagged",
+	"missing-reason",
+	"max-nesting",
+	"max-title-length",
+	"allowed-tags",
+}
+
+var lintSeverities = []string{"error", "warning", "off"}
+
+// defaultLintRules are enabled without configuration. The other rules need
+// a parameter or are a matter of taste.
+func defaultLintRules() map[string]LintRule {
+	return map[string]LintRule{
+		"duplicate-title": {Severity: "error"},
+		"missing-reason":  {Severity: "warning"},
+	}
+}
+
+// lintRules merges the configured rules over the defaults. A configured rule
+// without a severity is a warning.
+func lintRules(cfg LintConfig) (map[string]LintRule, error) {
+	rules := defaultLintRules()
+	for name, rule := range cfg.Rules {
+		if !slices.Contains(lintRuleNames, name) {
+			return nil, fmt.Errorf("unknown lint rule %q, expected one of %s", name, strings.Join(lintRuleNames, ", "))
+		}
+		if rule.Severity == "" {
+			rule.Severity = "warning"
+		}
+		if !slices.Contains(lintSeverities, rule.Severity) {
+			return nil, fmt.Errorf("invalid severity %q for lint rule %s, expected error, warning or off", rule.Severity, name)
+		}
+		if rule.Severity != "off" {
+			switch name {
+			case "max-nesting", "max-title-length":
+				if rule.Max < 1 {
+					return nil, fmt.Errorf("lint rule %s needs a positive \"max\"", name)
+				}
+			case "allowed-tags":
+				if len(rule.Tags) == 0 {
+					return nil, fmt.Errorf("lint rule %s needs a list of \"tags\"", name)
+				}
+			}
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+type lintFinding struct {
+	Rule     string
+	Severity string
+	File     string
+	Line     int
+	Message  string
+}
+
+// lintReport checks the suites of the report against the enabled rules and
+// returns the findings sorted by file and line.
+func lintReport(report PlaywrightJSON, rules map[string]LintRule) []lintFinding {
+	var findings []lintFinding
+	add := func(rule, file string, line int, format string, args ...any) {
+		if r, ok := rules[rule]; ok && r.Severity != "off" {
+			findings = append(findings, lintFinding{
+				Rule:     rule,
+				Severity: r.Severity,
+				File:     file,
+				Line:     line,
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+	}
+
+	var allowed []string
+	for _, tag := range rules["allowed-tags"].Tags {
+		allowed = append(allowed, normalizeTag(tag))
+	}
+
+	var lintSuite func(suite Suite, file string, depth int)
+	lintSuite = func(suite Suite, file string, depth int) {
+		if suite.Title != "" && suite.Title != suite.File {
+			depth++
+			if limit := rules["max-nesting"].Max; limit > 0 && depth == limit+1 {
+				add("max-nesting", file, suiteLine(suite), "describe %q is nested %d levels deep (max %d)", suite.Title, depth, limit)
+			}
+		}
+
+		// Reports list a spec once per project in some Playwright versions,
+		// so a title repeated at the same line is the same spec.
+		firstLine := map[string]int{}
+		seen := map[string]bool{}
+		reported := map[string]bool{}
+		for _, spec := range suite.Specs {
+			for _, test := range spec.Tests {
+				for _, ann := range test.Annotations {
+					annotation := fmt.Sprintf("%d:%s:%s", spec.Line, spec.Title, ann.Type)
+					if (ann.Type == "skip" || ann.Type == "fixme") && strings.TrimSpace(ann.Description) == "" && !reported[annotation] {
+						reported[annotation] = true
+						add("missing-reason", file, spec.Line, "%q is marked %s without a reason", spec.Title, ann.Type)
+					}
+				}
+			}
+
+			key := fmt.Sprintf("%d:%s", spec.Line, spec.Title)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if line, ok := firstLine[spec.Title]; ok {
+				add("duplicate-title", file, spec.Line, "%q is also the title of the test at line %d", spec.Title, line)
+			} else {
+				firstLine[spec.Title] = spec.Line
+			}
+
+			if len(spec.Tags) == 0 {
+				add("untagged", file, spec.Line, "%q has no tags", spec.Title)
+			}
+			if limit := rules["max-title-length"].Max; limit > 0 {
+				if length := utf8.RuneCountInString(spec.Title); length > limit {
+					add("max-title-length", file, spec.Line, "%q is %d characters long (max %d)", spec.Title, length, limit)
+				}
+			}
+			if len(allowed) > 0 {
+				for _, tag := range spec.Tags {
+					if !slices.Contains(allowed, normalizeTag(tag)) {
+						add("allowed-tags", file, spec.Line, "%q has tag %s, which is not allowed", spec.Title, tag)
+					}
+				}
+			}
+		}
+
+		for _, child := range suite.Suites {
+			lintSuite(child, file, depth)
+		}
+	}
+
+	for _, top := range report.Suites {
+		if top.File == "" {
+			continue
+		}
+		lintSuite(top, top.File, 0)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// buildLintTree groups the findings by file.
+func buildLintTree(findings []lintFinding) *treeNode {
+	root := &treeNode{Kind: rootNode}
+	var file *treeNode
+	for _, f := range findings {
+		if file == nil || file.File != f.File {
+			file = &treeNode{Kind: fileNode, Title: f.File, File: f.File}
+			root.Children = append(root.Children, file)
+		}
+		file.Children = append(file.Children, &treeNode{
+			Kind:   detailNode,
+			Detail: "finding",
+			Status: f.Severity,
+			Title:  f.Rule + ": " + f.Message,
+			File:   f.File,
+			Line:   f.Line,
+		})
+	}
+	return root
+}
+
+// lintCounter sums up the findings, e.g. "2 errors, 1 warning in 2 files".
+func lintCounter(findings []lintFinding) string {
+	if len(findings) == 0 {
+		return "No lint findings"
+	}
+	severities := map[string]int{}
+	files := map[string]bool{}
+	for _, f := range findings {
+		severities[f.Severity]++
+		files[f.File] = true
+	}
+	var parts []string
+	for _, severity := range []string{"error", "warning"} {
+		if n := severities[severity]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s%s", n, severity, pluralize(n)))
+		}
+	}
+	return fmt.Sprintf("%s in %d file%s", strings.Join(parts, ", "), len(files), pluralize(len(files)))
+}
+
+func findingLabel(n *treeNode, styles map[string]lipgloss.Style) string {
+	style := styles["failed"]
+	if n.Status == "warning" {
+		style = styles["flaky"]
+	}
+	label := style.Render(n.Status) + " " + styles["test"].Render(n.Title)
+	if n.Line > 0 {
+		label += styles["fileLine"].Render(fmt.Sprintf(" (%s:%d)", n.File, n.Line))
+	}
+	return label
+}
+
+// renderMarkdownLint folds the findings of each file into a <details> block,
+// like the markdown tree.
+func renderMarkdownLint(nodes *treeNode, findings []lintFinding) string {
+	var b strings.Builder
+	b.WriteString("**" + lintCounter(findings) + "**\n")
+	for _, file := range nodes.Children {
+		n := len(file.Children)
+		fmt.Fprintf(&b, "\n<details>\n<summary><code>%s</code> (%d finding%s)</summary>\n\n",
+			html.EscapeString(file.Title), n, pluralize(n))
+		for _, f := range file.Children {
+			label := "**" + f.Status + "** " + markdownEscaper.Replace(f.Title)
+			if f.Line > 0 {
+				label += fmt.Sprintf(" `%s:%d`", f.File, f.Line)
+			}
+			b.WriteString("- " + label + "\n")
+		}
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// runLint prints the findings for the report and returns false when any of
+// them is an error.
+func runLint(report PlaywrightJSON, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) (bool, error) {
+	cfg, _, err := readConfig()
+	if err != nil {
+		return false, fmt.Errorf("parsing config: %v", err)
+	}
+	rules, err := lintRules(cfg.Lint)
+	if err != nil {
+		return false, err
+	}
+
+	findings := lintReport(report, rules)
+	nodes := buildLintTree(findings)
+	if outputFormat == "json" {
+		out := jsonTree(nodes)
+		out.Summary = []string{lintCounter(findings)}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		fmt.Println(string(data))
+	} else if outputFormat == "markdown" {
+		fmt.Print(renderMarkdownLint(nodes, findings))
+	} else {
+		fmt.Println(renderTree(nodes, styles, display, emojis, lintCounter(findings)))
+	}
+
+	for _, f := range findings {
+		if f.Severity == "error" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
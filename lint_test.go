@@ -0,0 +1,132 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func lintFixture() PlaywrightJSON {
+	chromium := []TestInstance{{ProjectName: "chromium"}}
+	return PlaywrightJSON{Suites: []Suite{
+		{Title: "cart.spec.ts", File: "cart.spec.ts", Suites: []Suite{
+			{Title: "Cart", File: "cart.spec.ts", Line: 3, Specs: []Spec{
+				{Title: "adds an item", File: "cart.spec.ts", Line: 4, Tags: []string{"@smoke"}, Tests: chromium},
+				// The same spec listed again for another project.
+				{Title: "adds an item", File: "cart.spec.ts", Line: 4, Tags: []string{"@smoke"}, Tests: []TestInstance{{ProjectName: "webkit"}}},
+				{Title: "adds an item", File: "cart.spec.ts", Line: 9, Tags: []string{"@cart"}, Tests: chromium},
+				{Title: "removes an item", File: "cart.spec.ts", Line: 14, Tags: []string{"@smoke"}, Tests: []TestInstance{
+					{ProjectName: "chromium", Annotations: []Annotation{{Type: "skip"}}},
+					{ProjectName: "webkit", Annotations: []Annotation{{Type: "skip"}}},
+				}},
+				{Title: "pays", File: "cart.spec.ts", Line: 20, Tags: []string{"@smoke"}, Tests: []TestInstance{
+					{ProjectName: "chromium", Annotations: []Annotation{{Type: "fixme", Description: "flaky payment sandbox"}}},
+				}},
+			}, Suites: []Suite{
+				{Title: "Coupons", File: "cart.spec.ts", Line: 25, Suites: []Suite{
+					{Title: "Expired", File: "cart.spec.ts", Line: 26, Specs: []Spec{
+						{Title: "rejects an expired coupon code at checkout", File: "cart.spec.ts", Line: 27, Tags: []string{"@smoke"}, Tests: chromium},
+					}},
+				}},
+			}},
+		}},
+	}}
+}
+
+func TestLintRules(t *testing.T) {
+	rules, err := lintRules(LintConfig{Rules: map[string]LintRule{
+		"untagged":        {},
+		"duplicate-title": {Severity: "off"},
+		"max-nesting":     {Severity: "error", Max: 2},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]LintRule{
+		"duplicate-title": {Severity: "off"},
+		"missing-reason":  {Severity: "warning"},
+		"untagged":        {Severity: "warning"},
+		"max-nesting":     {Severity: "error", Max: 2},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected %v, got %v", expected, rules)
+	}
+
+	for _, cfg := range []map[string]LintRule{
+		{"no-such-rule": {}},
+		{"untagged": {Severity: "fatal"}},
+		{"max-title-length": {}},
+		{"allowed-tags": {Severity: "error"}},
+	} {
+		if _, err := lintRules(LintConfig{Rules: cfg}); err == nil {
+			t.Errorf("Expected an error for %v", cfg)
+		}
+	}
+	if _, err := lintRules(LintConfig{Rules: map[string]LintRule{"max-nesting": {Severity: "off"}}}); err != nil {
+		t.Errorf("Expected disabled rules to need no parameters, got %v", err)
+	}
+}
+
+func TestLintReport(t *testing.T) {
+	rules, _ := lintRules(LintConfig{Rules: map[string]LintRule{
+		"untagged":         {},
+		"max-nesting":      {Max: 2},
+		"max-title-length": {Severity: "error", Max: 40},
+		"allowed-tags":     {Tags: []string{"smoke"}},
+	}})
+
+	var got []string
+	for _, f := range lintReport(lintFixture(), rules) {
+		got = append(got, f.Severity+" "+f.File+":"+strconv.Itoa(f.Line)+" "+f.Rule)
+	}
+	expected := []string{
+		"error cart.spec.ts:9 duplicate-title",
+		"warning cart.spec.ts:9 allowed-tags",
+		"warning cart.spec.ts:14 missing-reason",
+		"warning cart.spec.ts:26 max-nesting",
+		"error cart.spec.ts:27 max-title-length",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected findings\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestRenderLintTree(t *testing.T) {
+	rules, _ := lintRules(LintConfig{})
+	findings := lintReport(lintFixture(), rules)
+	output := renderTree(buildLintTree(findings), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{}, lintCounter(findings))
+
+	for _, line := range []string{
+		"cart.spec.ts",
+		`error duplicate-title: "adds an item" is also the title of the test at line 4 (cart.spec.ts:9)`,
+		`warning missing-reason: "removes an item" is marked skip without a reason (cart.spec.ts:14)`,
+		"1 error, 1 warning in 1 file",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got\n%s", line, output)
+		}
+	}
+	if lintCounter(nil) != "No lint findings" {
+		t.Errorf("Unexpected counter without findings: %q", lintCounter(nil))
+	}
+}
+
+func TestRenderMarkdownLint(t *testing.T) {
+	rules, _ := lintRules(LintConfig{})
+	findings := lintReport(lintFixture(), rules)
+	output := renderMarkdownLint(buildLintTree(findings), findings)
+
+	for _, line := range []string{
+		"**1 error, 1 warning in 1 file**",
+		"<summary><code>cart.spec.ts</code> (2 findings)</summary>",
+		"- **error** duplicate-title: \"adds an item\" is also the title of the test at line 4 `cart.spec.ts:9`",
+		"- **warning** missing-reason: \"removes an item\" is marked skip without a reason `cart.spec.ts:14`",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("Expected output to contain %q, got\n%s", line, output)
+		}
+	}
+}
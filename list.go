@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -29,16 +30,30 @@ func (m *multiFlag) Set(value string) error {
 	return nil
 }
 
-func runPlaywrightList(projects multiFlag, onlyChanged, lastFailed bool, config string) []byte {
-	args := []string{"playwright", "test", "--list", "--reporter=json"}
+func (e *PlaywrightError) Error() string {
+	return e.Message
+}
 
-	if config != "" {
-		args = append(args, "--config", config)
-	}
+func runPlaywrightList(projects multiFlag, onlyChanged, lastFailed bool, config, grep, grepInvert string) []byte {
+	args := playwrightListArgs(projects, onlyChanged, lastFailed, config, grep, grepInvert)
 
-	for _, p := range projects {
-		args = append(args, "--project", p)
+	out, err := listPlaywright("", args)
+	var pwErr *PlaywrightError
+	if errors.As(err, &pwErr) {
+		fmt.Println(pwErr.Message)
+		os.Exit(1)
 	}
+	if err != nil {
+		fmt.Println("Error running Playwright:", err)
+		os.Exit(1)
+	}
+
+	return out
+}
+
+func playwrightListArgs(projects []string, onlyChanged, lastFailed bool, config, grep, grepInvert string) []string {
+	args := []string{"playwright", "test", "--list", "--reporter=json"}
+	args = appendPlaywrightArgs(args, projects, config)
 
 	if onlyChanged {
 		args = append(args, "--only-changed")
@@ -46,25 +61,68 @@ func runPlaywrightList(projects multiFlag, onlyChanged, lastFailed bool, config
 	if lastFailed {
 		args = append(args, "--last-failed")
 	}
+	if grep != "" {
+		args = append(args, "--grep", grep)
+	}
+	if grepInvert != "" {
+		args = append(args, "--grep-invert", grepInvert)
+	}
 
+	return args
+}
+
+// listPlaywright runs `npx` with the given list arguments in dir, or in the
+// current directory if dir is empty. Errors reported by Playwright itself are
+// returned as a *PlaywrightError.
+func listPlaywright(dir string, args []string) ([]byte, error) {
 	cmd := exec.Command("npx", args...)
-	fmt.Println("Running command:", "npx", strings.Join(args, " "))
+	cmd.Dir = dir
+	if dir != "" {
+		fmt.Fprintln(os.Stderr, "Running command:", "npx", strings.Join(args, " "), "in", dir)
+	} else {
+		fmt.Fprintln(os.Stderr, "Running command:", "npx", strings.Join(args, " "))
+	}
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = os.Stderr
 
 	var parsed PlaywrightOutput
 	if err := cmd.Run(); err != nil {
-		if err := json.Unmarshal(out.Bytes(), &parsed); err == nil {
-			if len(parsed.Errors) > 0 {
-				fmt.Println(parsed.Errors[0].Message)
-				os.Exit(1)
-			}
-		} else {
-			fmt.Println("Error running Playwright:", err)
-			os.Exit(1)
+		if jsonErr := json.Unmarshal(out.Bytes(), &parsed); jsonErr != nil {
+			return nil, err
 		}
+		if len(parsed.Errors) > 0 {
+			return nil, &parsed.Errors[0]
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+func appendPlaywrightArgs(args []string, projects []string, config string) []string {
+	if config != "" {
+		args = append(args, "--config", config)
+	}
+
+	for _, p := range projects {
+		args = append(args, "--project", p)
 	}
 
-	return out.Bytes()
+	return args
+}
+
+// runPlaywrightTests executes the given file or file:line targets,
+// streaming Playwright's own output to the terminal.
+func runPlaywrightTests(targets []string, projects []string, config string) error {
+	args := []string{"playwright", "test"}
+	args = append(args, targets...)
+	args = appendPlaywrightArgs(args, projects, config)
+
+	cmd := exec.Command("npx", args...)
+	fmt.Println("Running command:", "npx", strings.Join(args, " "))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
 }
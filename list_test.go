@@ -37,3 +37,11 @@ func TestMultiFlagString(t *testing.T) {
 		t.Errorf("Expected string %q, got %q", expected, result)
 	}
 }
+
+func TestAppendPlaywrightArgs(t *testing.T) {
+	args := appendPlaywrightArgs([]string{"playwright", "test", "a.spec.ts:4"}, []string{"chromium", "webkit"}, "pw.config.ts")
+	expected := []string{"playwright", "test", "a.spec.ts:4", "--config", "pw.config.ts", "--project", "chromium", "--project", "webkit"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %v, got %v", expected, args)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lockfileVersion is bumped whenever the lockfile format changes in a way
+// older versions of pwtree cannot read.
+const lockfileVersion = 1
+
+// Lockfile is the canonical inventory written by `pwtree snapshot`. Specs are
+// sorted by file, describe titles and title, and have no lines, so the
+// lockfile only changes when tests are added, removed, renamed, retagged or
+// run in other projects.
+type Lockfile struct {
+	Version int          `json:"version"`
+	Specs   []LockedSpec `json:"specs"`
+}
+
+type LockedSpec struct {
+	ID        string   `json:"id,omitempty"`
+	File      string   `json:"file"`
+	Describes []string `json:"describes,omitempty"`
+	Title     string   `json:"title"`
+	Tags      []string `json:"tags,omitempty"`
+	Projects  []string `json:"projects"`
+}
+
+func buildLockfile(entries []inventoryEntry) Lockfile {
+	sorted := append([]inventoryEntry{}, entries...)
+	// A stable sort keeps specs sharing a title in their order, which
+	// their keys depend on.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lockOrder(sorted[i]) < lockOrder(sorted[j])
+	})
+
+	lock := Lockfile{Version: lockfileVersion, Specs: []LockedSpec{}}
+	for _, e := range sorted {
+		lock.Specs = append(lock.Specs, LockedSpec{
+			ID:        e.ID,
+			File:      e.File,
+			Describes: e.Describes,
+			Title:     e.Title,
+			Tags:      e.Tags,
+			Projects:  append([]string{}, e.Projects...),
+		})
+	}
+	return lock
+}
+
+func lockOrder(e inventoryEntry) string {
+	return strings.Join(append(append([]string{e.File}, e.Describes...), e.Title), "\x00")
+}
+
+// inventory returns the locked specs in the form compared by diffs.
+func (l Lockfile) inventory() []inventoryEntry {
+	var entries []inventoryEntry
+	for _, s := range l.Specs {
+		entries = append(entries, inventoryEntry{
+			ID:        s.ID,
+			File:      s.File,
+			Describes: s.Describes,
+			Title:     s.Title,
+			Tags:      s.Tags,
+			Projects:  s.Projects,
+		})
+	}
+	assignKeys(entries)
+	return entries
+}
+
+func renderLockfile(entries []inventoryEntry) (string, error) {
+	data, err := json.MarshalIndent(buildLockfile(entries), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func readLockfile(path string) (Lockfile, error) {
+	var lock Lockfile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lock, err
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, fmt.Errorf("parsing %s: %v", path, err)
+	}
+	if lock.Version != lockfileVersion {
+		return lock, fmt.Errorf("%s has version %d, expected %d", path, lock.Version, lockfileVersion)
+	}
+	return lock, nil
+}
+
+// lockedInventory drops the annotations, which lockfiles do not record, from
+// the entries. Lines are kept for display; they are not compared against
+// the lockfile's missing ones.
+func lockedInventory(entries []inventoryEntry) []inventoryEntry {
+	locked := append([]inventoryEntry{}, entries...)
+	for i := range locked {
+		locked[i].Annotations = nil
+		locked[i].ProjectAnnotations = nil
+	}
+	return locked
+}
+
+// checkLockfile compares the current inventory with the lockfile, printing
+// the differences. It returns false when there are any.
+func checkLockfile(path string, current []inventoryEntry, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) (bool, error) {
+	lock, err := readLockfile(path)
+	if err != nil {
+		return false, err
+	}
+	locked, now := lock.inventory(), lockedInventory(current)
+	if len(diffInventories(locked, now)) == 0 {
+		tests := 0
+		for _, e := range now {
+			tests += len(e.Projects)
+		}
+		fmt.Printf("%d test%s match %s\n", tests, pluralize(tests), path)
+		return true, nil
+	}
+
+	printDiff(locked, now, styles, display, emojis)
+	fmt.Fprintf(os.Stderr, "\nThe tests differ from %s. If the changes are deliberate, update it with 'pwtree snapshot > %s'.\n", path, path)
+	return false, nil
+}
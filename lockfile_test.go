@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestBuildLockfile(t *testing.T) {
+	report := PlaywrightJSON{Suites: []Suite{
+		{Title: "b.spec.ts", File: "b.spec.ts", Specs: []Spec{
+			{ID: "b2", Title: "same", File: "b.spec.ts", Line: 9, Tests: []TestInstance{{ProjectName: "webkit"}, {ProjectName: "chromium"}}},
+			{ID: "b1", Title: "same", File: "b.spec.ts", Line: 3, Tags: []string{"@smoke"}, Tests: []TestInstance{{ProjectName: "chromium"}}},
+		}},
+		{Title: "a.spec.ts", File: "a.spec.ts", Suites: []Suite{
+			{Title: "Z", File: "a.spec.ts", Line: 1, Specs: []Spec{
+				{ID: "a1", Title: "first", File: "a.spec.ts", Line: 2, Tests: []TestInstance{{ProjectName: "chromium", Annotations: []Annotation{{Type: "skip"}}}}},
+			}},
+		}},
+	}}
+
+	lock := buildLockfile(inventory(report))
+	expected := []LockedSpec{
+		{ID: "a1", File: "a.spec.ts", Describes: []string{"Z"}, Title: "first", Projects: []string{"chromium"}},
+		{ID: "b2", File: "b.spec.ts", Title: "same", Projects: []string{"chromium", "webkit"}},
+		{ID: "b1", File: "b.spec.ts", Title: "same", Tags: []string{"@smoke"}, Projects: []string{"chromium"}},
+	}
+	if lock.Version != lockfileVersion || !reflect.DeepEqual(lock.Specs, expected) {
+		t.Errorf("Expected sorted specs\n%+v\ngot\n%+v", expected, lock.Specs)
+	}
+
+	// Reading the lockfile back numbers duplicate titles as the report does.
+	var keys []string
+	for _, e := range lock.inventory() {
+		keys = append(keys, e.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"a.spec.ts › Z › first", "b.spec.ts › same", "b.spec.ts › same #2"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+}
+
+func TestCheckLockfile(t *testing.T) {
+	old, new := diffFixtures()
+	path := filepath.Join(t.TempDir(), "pwtree.lock")
+	out, err := renderLockfile(inventory(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(path, []byte(out), 0644)
+
+	// Moved lines and annotations are not recorded, so they do not count.
+	moved := inventory(old)
+	moved[0].Line += 10
+	moved[1].Annotations = []string{"skip"}
+	ok, err := checkLockfile(path, moved, map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	if err != nil || !ok {
+		t.Errorf("Expected the unchanged inventory to match, got %v, %v", ok, err)
+	}
+
+	ok, err = checkLockfile(path, inventory(new), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	if err != nil || ok {
+		t.Errorf("Expected the changed inventory not to match, got %v, %v", ok, err)
+	}
+
+	os.WriteFile(path, []byte(`{"version": 99, "specs": []}`), 0644)
+	if _, err := checkLockfile(path, inventory(new), map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{}); err == nil {
+		t.Errorf("Expected an error for an unknown lockfile version")
+	}
+}
+
+func TestRenderDiffView_Lockfile(t *testing.T) {
+	works := func(line int) []Spec {
+		return []Spec{{Title: "works", File: "a.spec.ts", Line: line, Tests: []TestInstance{{ProjectName: "chromium"}}}}
+	}
+	report := PlaywrightJSON{Suites: []Suite{{Title: "a.spec.ts", File: "a.spec.ts", Suites: []Suite{
+		{Title: "Cart", File: "a.spec.ts", Line: 3, Specs: works(4)},
+		{Title: "Checkout", File: "a.spec.ts", Line: 10, Specs: works(11)},
+	}}}}
+	locked := buildLockfile(inventory(report)).inventory()
+
+	output := renderDiffView(locked, nil, map[string]lipgloss.Style{}, DisplayOptions{ShowFileLines: true}, DisplayEmojis{})
+	if strings.Count(output, "- works") != 2 {
+		t.Errorf("Expected both removed specs to be listed, got\n%s", output)
+	}
+	if strings.Contains(output, ":0") {
+		t.Errorf("Expected no file:line for lockfile entries, got\n%s", output)
+	}
+}
@@ -5,37 +5,68 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-	projects      multiFlag
-	configFile    string
-	onlyChanged   = flag.Bool("only-changed", false, "Show only tests related to changed files")
-	lastFailed    = flag.Bool("last-failed", false, "Show only tests that failed last run")
-	showSkipped   = flag.Bool("skipped", false, "Show only tests with [skipped] annotation")
-	showFixme     = flag.Bool("fixme", false, "Show only tests with [fixme] annotation")
-	showFail      = flag.Bool("fail", false, "Show only tests with [fail] annotation")
-	titleStyle    = lipgloss.NewStyle().Bold(true)
-	jsonDataPath  string
-	ciMode        = flag.Bool("ci", false, "Disable colors and emojis for CI environments")
-	filterString  string
-	helpRequested = flag.Bool("help", false, "Show this help message")
+	projects        multiFlag
+	configFile      string
+	onlyChanged     = flag.Bool("only-changed", false, "Show only tests related to changed files")
+	lastFailed      = flag.Bool("last-failed", false, "Show only tests that failed last run")
+	showSkipped     = flag.Bool("skipped", false, "Show only tests with [skipped] annotation")
+	showFixme       = flag.Bool("fixme", false, "Show only tests with [fixme] annotation")
+	showFail        = flag.Bool("fail", false, "Show only tests with [fail] annotation")
+	titleStyle      = lipgloss.NewStyle().Bold(true)
+	jsonDataPaths   pathsFlag
+	assertExprs     pathsFlag
+	ciMode          = flag.Bool("ci", false, "Disable colors and emojis for CI environments")
+	filterString    string
+	ignoreCase      = flag.Bool("ignore-case", false, "Match filter and query terms case-insensitively")
+	queryString     string
+	grepPattern     string
+	grepInvert      string
+	outputFormat    string
+	sortBy          string
+	groupBy         string
+	htmlPath        string
+	top             int
+	diffAgainst     string
+	groupByDir      = flag.Bool("group-by-dir", false, "Nest files under their directories")
+	compactDirs     = flag.Bool("compact-dirs", false, "With --group-by-dir, merge directories that have a single child")
+	interactive     = flag.Bool("interactive", false, "Browse the tree interactively")
+	showErrors      = flag.Bool("errors", false, "Show the errors of failed results under their tests")
+	showSteps       = flag.Bool("steps", false, "Show the step tree of each result under its test")
+	showAttachments = flag.Bool("attachments", false, "Show the attachments of each result under its test")
+	heatmap         = flag.Bool("heatmap", false, "Color nodes by the duration of their tests")
+	workspace       = flag.Bool("workspace", false, "List every Playwright config beneath the current directory")
+	helpRequested   = flag.Bool("help", false, "Show this help message")
 )
 
 func init() {
 	flag.Var(&projects, "project", "Project(s) to filter (space-separated or repeatable)")
 	flag.StringVar(&filterString, "filter", "", "Comma-separated list of filter terms. Use -prefix for exclusion.")
+	flag.StringVar(&queryString, "query", "", "Filter expression, e.g. 'tag:@smoke and not project:webkit'")
+	flag.StringVar(&grepPattern, "grep", "", "Only show tests matching this regular expression, like 'playwright test --grep'")
+	flag.StringVar(&grepInvert, "grep-invert", "", "Only show tests not matching this regular expression, like 'playwright test --grep-invert'")
+	flag.StringVar(&sortBy, "sort", "line", "Order of files, suites and tests: line, title, tag, projects or annotation")
+	flag.StringVar(&groupBy, "group-by", "file", "Top-level grouping: file, tag, project or annotation")
+	flag.StringVar(&outputFormat, "format", "tree", "Output format: tree, json or markdown")
+	flag.IntVar(&top, "top", 20, "Number of tests listed by 'pwtree slowest'")
+	flag.StringVar(&diffAgainst, "against", "", "Git ref whose tests 'pwtree diff' compares against")
+	flag.StringVar(&htmlPath, "html", "", "Path of the HTML file written by 'pwtree export'")
 	flag.StringVar(&configFile, "config", "", "Path to Playwright config file")
 	flag.StringVar(&configFile, "c", "", "Shorthand for --config")
-	flag.StringVar(&jsonDataPath, "json-data-path", "", "Path to existing JSON file housing output of 'npx playwright test --list --reporter=json'")
+	flag.Var(&assertExprs, "assert", "Check a metric of the filtered tests, e.g. 'skipped<=10' or 'tests(project=webkit)>=500' (repeatable)")
+	flag.Var(&jsonDataPaths, "json-data-path", "Path or glob of existing JSON file(s) housing output of 'npx playwright test --list --reporter=json' (repeatable)")
 	flag.BoolVar(helpRequested, "h", false, "Shorthand for --help")
 }
 
 func main() {
-	flag.Parse()
+	command, args := parseArgs(os.Args[1:])
 
 	styles, display, emojis := loadStyleConfig()
 
@@ -44,48 +75,318 @@ func main() {
 		os.Exit(0)
 	}
 
-	var raw []byte
-	var err error
-	if jsonDataPath != "" {
-		raw, err = os.ReadFile(jsonDataPath)
+	switch outputFormat {
+	case "tree", "json", "markdown":
+	default:
+		fmt.Printf("Unknown format: %s\n", outputFormat)
+		os.Exit(1)
+	}
+
+	if !slices.Contains(sortModes, sortBy) {
+		fmt.Printf("Unknown sort order: %s\n", sortBy)
+		os.Exit(1)
+	}
+	if !slices.Contains(groupByModes, groupBy) {
+		fmt.Printf("Unknown grouping: %s\n", groupBy)
+		os.Exit(1)
+	}
+
+	assertions, err := parseAssertions(assertExprs)
+	if err != nil {
+		fmt.Printf("Error parsing --assert: %v\n", err)
+		os.Exit(1)
+	}
+	if len(assertions) > 0 {
+		if err := checkAssertUsage(command, *workspace); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if command == "diff" {
+		runDiff(args, styles, display, emojis)
+		return
+	}
+
+	if *workspace {
+		runWorkspace(command, styles, display, emojis)
+		return
+	}
+
+	// Positional arguments are data paths, as in `pwtree -` or
+	// `pwtree web.json admin.json.gz`, except for the commands taking their
+	// own.
+	args, dataPaths := splitDataPaths(command, args)
+	if len(dataPaths) > 0 {
+		if len(jsonDataPaths) > 0 {
+			fmt.Printf("Unexpected argument: %s\n", dataPaths[0])
+			os.Exit(1)
+		}
+		jsonDataPaths = dataPaths
+	}
+	// Usage errors are reported before listing the tests, which can be slow.
+	if usage := commandUsage(command, args); usage != "" {
+		fmt.Println("Usage: " + usage)
+		os.Exit(1)
+	}
+	if command == "open-trace" && slices.Contains(jsonDataPaths, "-") {
+		fmt.Println("Error: open-trace asks which trace to open on stdin, so it cannot read the report from it")
+		os.Exit(1)
+	}
+
+	pwData, err := loadCurrentReport()
+	if err != nil {
+		fmt.Printf("Error reading JSON data: %v\n", err)
+		os.Exit(1)
+	}
+
+	pwData = filterReport(pwData)
+
+	if len(assertions) > 0 {
+		out, ok := checkAssertions(assertions, pwData, styles)
+		fmt.Print(out)
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
+	switch command {
+	case "":
+	case "run":
+		nodes, err := resolveTargets(buildNodeTree(pwData), args)
+		if err != nil {
+			fmt.Printf("Error resolving tests: %v\n", err)
+			os.Exit(1)
+		}
+		runNodes(nodes)
+		return
+	case "slowest":
+		fmt.Print(buildSlowestView(buildNodeTree(pwData), top, styles, display, heatmapGradient))
+		return
+	case "open-trace":
+		nodes, err := resolveTargets(buildNodeTree(pwData), args)
+		if err != nil {
+			fmt.Printf("Error resolving tests: %v\n", err)
+			os.Exit(1)
+		}
+		openTraces(nodes, args[0], pwData.Config.RootDir)
+		return
+	case "snapshot":
+		out, err := renderLockfile(inventory(pwData))
+		if err != nil {
+			fmt.Printf("Error encoding lockfile: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+		return
+	case "check":
+		ok, err := checkLockfile(args[0], inventory(pwData), styles, display, emojis)
+		if err != nil {
+			fmt.Printf("Error checking lockfile: %v\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	case "lint":
+		ok, err := runLint(pwData, styles, display, emojis)
+		if err != nil {
+			fmt.Printf("Error running lint: %v\n", err)
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	case "export":
+		page, err := buildHTMLExport(pwData, styles, emojis)
+		if err != nil {
+			fmt.Printf("Error rendering HTML: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(htmlPath, []byte(page), 0644); err != nil {
+			fmt.Printf("Error writing HTML: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Exported tree to", htmlPath)
+		return
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		os.Exit(1)
+	}
+
+	if *interactive {
+		selected, err := runInteractive(buildNodeTree(pwData), styles, display, emojis)
 		if err != nil {
-			fmt.Printf("Error reading JSON data from file: %v\n", err)
+			fmt.Printf("Error running interactive mode: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
-		raw = runPlaywrightList(projects, *onlyChanged, *lastFailed, configFile)
+		if len(selected) > 0 {
+			runNodes(selected)
+		}
+		return
 	}
 
-	var pwData PlaywrightJSON
-	if err := json.Unmarshal(raw, &pwData); err != nil {
-		fmt.Printf("Error parsing JSON: %v\n", err)
+	if outputFormat == "json" {
+		out, err := buildJSONView(pwData)
+		if err != nil {
+			fmt.Printf("Error encoding JSON output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+		return
+	}
+
+	if outputFormat == "markdown" {
+		fmt.Print(buildMarkdownView(pwData, display))
+		return
+	}
+
+	filteredRaw, err := json.Marshal(pwData)
+	if err != nil {
+		fmt.Printf("Error encoding filtered JSON: %v\n", err)
 		os.Exit(1)
 	}
 
+	fmt.Println(buildTreeView(filteredRaw, styles, display, emojis))
+}
+
+// loadCurrentReport reads the --json-data-path reports, or lists the tests
+// with Playwright when there are none.
+func loadCurrentReport() (PlaywrightJSON, error) {
+	if len(jsonDataPaths) > 0 {
+		return loadReports(jsonDataPaths)
+	}
+	raw := runPlaywrightList(projects, *onlyChanged, *lastFailed, configFile, grepPattern, grepInvert)
+	return loadPlaywrightJSON(raw)
+}
+
+// filterReport applies the project, filter, query, grep and annotation flags.
+func filterReport(report PlaywrightJSON) PlaywrightJSON {
 	if len(projects) > 0 {
 		projectSet := map[string]bool{}
 		for _, p := range projects {
 			projectSet[p] = true
 		}
-		pwData.Suites = filterSuitesByProject(pwData.Suites, projectSet)
+		report.Suites = filterSuitesByProject(report.Suites, projectSet)
 	}
 
 	if filterString != "" {
 		terms := strings.Split(filterString, ";")
-		pwData.Suites = filterSuitesByFilter(pwData.Suites, terms)
+		if err := validateFilterTerms(terms); err != nil {
+			fmt.Printf("Error parsing filter: %v\n", err)
+			os.Exit(1)
+		}
+		report.Suites = filterSuitesByFilter(report.Suites, terms)
+	}
+
+	if queryString != "" {
+		expr, err := parseQuery(queryString)
+		if err != nil {
+			fmt.Printf("Error parsing query: %s\n", formatQueryError(queryString, err))
+			os.Exit(1)
+		}
+		report.Suites = filterSuitesByQuery(report.Suites, expr)
+	}
+
+	if grepPattern != "" || grepInvert != "" {
+		var grep, invert *regexp.Regexp
+		var err error
+		if grepPattern != "" {
+			if grep, err = compileGrep(grepPattern); err != nil {
+				fmt.Printf("Error parsing --grep: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if grepInvert != "" {
+			if invert, err = compileGrep(grepInvert); err != nil {
+				fmt.Printf("Error parsing --grep-invert: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		report.Suites = filterSuitesByGrep(report.Suites, grep, invert)
 	}
 
 	if *showFail || *showSkipped || *showFixme {
-		pwData.Suites = filterSuitesByAnnotation(pwData.Suites, *showSkipped, *showFixme, *showFail)
+		report.Suites = filterSuitesByAnnotation(report.Suites, *showSkipped, *showFixme, *showFail)
 	}
 
-	filteredRaw, err := json.Marshal(pwData)
-	if err != nil {
-		fmt.Printf("Error encoding filtered JSON: %v\n", err)
-		os.Exit(1)
+	return report
+}
+
+// splitDataPaths separates the arguments of a command from the data paths
+// among its positional arguments. The arguments after the lockfile of
+// `pwtree check` are data paths.
+func splitDataPaths(command string, positional []string) (args, dataPaths []string) {
+	switch command {
+	case "run", "open-trace":
+		return positional, nil
+	case "check":
+		if len(positional) > 1 {
+			return positional[:1], positional[1:]
+		}
+		return positional, nil
 	}
+	return nil, positional
+}
 
-	fmt.Println(buildTreeView(filteredRaw, styles, display, emojis))
+// commandUsage returns the usage of the command when its arguments or flags
+// are missing, or "" when they are complete.
+func commandUsage(command string, args []string) string {
+	switch command {
+	case "run":
+		if len(args) == 0 {
+			return "pwtree run <file[:line]>..."
+		}
+	case "slowest":
+		if top < 1 {
+			return "pwtree slowest --top <number of tests>"
+		}
+	case "open-trace":
+		if len(args) != 1 {
+			return "pwtree open-trace <file[:line]> --json-data-path <results.json>"
+		}
+	case "check":
+		if len(args) != 1 {
+			return "pwtree check <pwtree.lock> [json file]..."
+		}
+	case "export":
+		if htmlPath == "" {
+			return "pwtree export --html <file path>"
+		}
+	}
+	return ""
+}
+
+var commands = []string{"", "run", "export", "open-trace", "slowest", "diff", "snapshot", "check", "lint"}
+
+// parseArgs parses the flags, allowing positional arguments to be
+// interleaved with them, and splits the subcommand from the positional
+// arguments.
+func parseArgs(args []string) (string, []string) {
+	var positional []string
+	for {
+		flag.CommandLine.Parse(args)
+		args = flag.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return splitCommand(positional)
+}
+
+// splitCommand returns the subcommand, when the first positional argument is
+// one, as in `pwtree --ci diff a.json b.json`, and the remaining arguments.
+func splitCommand(positional []string) (string, []string) {
+	if len(positional) > 0 && positional[0] != "" && slices.Contains(commands, positional[0]) {
+		return positional[0], positional[1:]
+	}
+	return "", positional
 }
 
 func printHelp(rootEmoji string) {
@@ -95,11 +396,37 @@ func printHelp(rootEmoji string) {
 	}
 
 	const helpText = `Usage:
-  pwtree [flags]
+  pwtree [flags] [json file | -]...
+  pwtree run [flags] <file[:line]>...
+  pwtree export --html [file path] [flags]
+  pwtree open-trace <file[:line]> [flags]
+  pwtree slowest [--top 20] [flags]
+  pwtree diff <old.json> [new.json] [flags]
+  pwtree diff --against <git ref> [new.json] [flags]
+  pwtree snapshot [flags] > pwtree.lock
+  pwtree check <pwtree.lock> [json file]... [flags]
+  pwtree lint [flags]
+
+Commands:
+  run                             Run the tests at the given file or file:line locations
+  export                          Write the tree to a self-contained HTML file
+  slowest                         List the slowest tests of a run report with their describe path
+  open-trace                      Open the trace of a test from a run report in the Playwright trace viewer
+  diff                            Show the specs added, removed or changed between two reports, or between
+                                  a report or git ref and the current list
+  snapshot                        Print a canonical inventory of spec ids, titles, tags and projects, to commit as a lockfile
+  check                           Exit with an error and show the differences when the tests no longer match a lockfile
+  lint                            Check the suite against the conventions configured under "lint" in .pwtree.json
 
 Flags:
   --project [project-name]        Project(s) to filter (space-separated or repeatable)
   --filter [filter-string]        Semicolon separated list of filter terms. Use - for exclusion.
+                                  Terms may be /regular expressions/i, file:globs/** or @whole-tags
+  --ignore-case                   Match filter and query terms case-insensitively
+  --query [expression]            Filter expression with and/or/not, parentheses and
+                                  title:, file:, tag:, project:, annotation:, line: terms
+  --grep [pattern]                Only show tests whose full title matches, exactly like 'playwright test --grep'
+  --grep-invert [pattern]         Only show tests whose full title does not match
   --only-changed                  Show only tests related to changed files
   --last-failed                   Show only tests that failed last run
   --skipped                       Show only tests with [skipped] annotation
@@ -107,7 +434,29 @@ Flags:
   --fail                          Show only tests with [fail] annotation
   --config, -c [file path]        Path to Playwright config file
   --json-data-path [file path]    Path to existing JSON file housing output of 'npx playwright test --list --reporter=json'
+                                  Use - to read from stdin. Gzip and zip files (e.g. CI artifacts) are unpacked.
+                                  Repeat it or pass a glob to merge several reports into one tree
+  --sort [order]                  Order of files, suites and tests: line (default), title, tag,
+                                  projects (most tests first) or annotation (skipped, fixme, fail first)
+  --group-by [grouping]           Top-level grouping: file (default), tag, project or annotation
+  --group-by-dir                  Nest files under their directories, with per-directory test counts
+  --compact-dirs                  With --group-by-dir, merge directories that have a single child
+  --format [tree|json|markdown]   Output format (default: tree)
+  --html [file path]              Path of the HTML file written by 'pwtree export'
   --ci                            Disable colors and emojis for CI environments
+  --errors                        For run reports, show each failed result's error message, location and code snippet
+  --steps                         For run reports, show the steps of each result with durations, failing steps highlighted
+  --attachments                   For run reports, show the traces, screenshots and videos of each result
+  --heatmap                       For run reports, color files, suites and tests by their total duration
+  --against [git ref]             With 'pwtree diff', list the tests of this ref (e.g. origin/main) in a
+                                  temporary git worktree and compare against them
+  --top [number]                  Number of tests listed by 'pwtree slowest' (default: 20)
+  --assert [expression]           Check a metric of the filtered tests and exit with an error when it does not hold,
+                                  e.g. 'skipped<=10', 'fixme==0', 'tests(project=webkit)>=500' (repeatable).
+                                  Cannot be combined with commands or --workspace
+  --interactive                   Browse the tree interactively (space to select, r to run)
+  --workspace                     List every playwright.config.{ts,js,mjs} beneath the current directory,
+                                  with one root node and total per config
   --help, -h                      Show this help message
 `
 	fmt.Printf("\n%s\n\n%s", titleStyle.Render(strings.TrimSpace(displayEmoji+" Playwright-tree")), helpText)
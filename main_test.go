@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	cases := []struct {
+		positional []string
+		command    string
+		args       []string
+	}{
+		{nil, "", nil},
+		{[]string{"report.json"}, "", []string{"report.json"}},
+		{[]string{"check", "pwtree.lock"}, "check", []string{"pwtree.lock"}},
+		{[]string{"diff", "a.json", "b.json"}, "diff", []string{"a.json", "b.json"}},
+		{[]string{"r.json", "lint"}, "", []string{"r.json", "lint"}},
+		{[]string{"run", "diff.spec.ts:3"}, "run", []string{"diff.spec.ts:3"}},
+	}
+	for _, c := range cases {
+		command, args := splitCommand(c.positional)
+		if command != c.command || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("splitCommand(%q) = %q, %q, expected %q, %q", c.positional, command, args, c.command, c.args)
+		}
+	}
+}
+
+func TestSplitDataPaths(t *testing.T) {
+	cases := []struct {
+		command    string
+		positional []string
+		args       []string
+		dataPaths  []string
+	}{
+		{"", []string{"a.json", "b.json"}, nil, []string{"a.json", "b.json"}},
+		{"lint", []string{"a.json"}, nil, []string{"a.json"}},
+		{"run", []string{"a.spec.ts:3"}, []string{"a.spec.ts:3"}, nil},
+		{"check", []string{"pwtree.lock"}, []string{"pwtree.lock"}, nil},
+		{"check", []string{"pwtree.lock", "report.json"}, []string{"pwtree.lock"}, []string{"report.json"}},
+	}
+	for _, c := range cases {
+		args, dataPaths := splitDataPaths(c.command, c.positional)
+		if !reflect.DeepEqual(args, c.args) || !reflect.DeepEqual(dataPaths, c.dataPaths) {
+			t.Errorf("splitDataPaths(%q, %q) = %q, %q, expected %q, %q", c.command, c.positional, args, dataPaths, c.args, c.dataPaths)
+		}
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	for _, command := range []string{"run", "open-trace", "check"} {
+		if commandUsage(command, nil) == "" {
+			t.Errorf("Expected the usage of %s without arguments", command)
+		}
+	}
+	if usage := commandUsage("check", []string{"pwtree.lock"}); usage != "" {
+		t.Errorf("Expected no usage for a complete check, got %q", usage)
+	}
+}
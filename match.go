@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// textMatcher reports whether a title, file or tag matches a filter value.
+type textMatcher func(s string) bool
+
+var regexTermPattern = regexp.MustCompile(`^/(.+)/([a-z]*)$`)
+
+// parseRegexTerm compiles /pattern/flags values. ok is false for values that
+// are not written as a regular expression.
+func parseRegexTerm(value string) (re *regexp.Regexp, ok bool, err error) {
+	m := regexTermPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil, false, nil
+	}
+	pattern, flags := m[1], m[2]
+	for _, f := range flags {
+		if !strings.ContainsRune("ims", f) {
+			return nil, true, fmt.Errorf("unsupported regular expression flag %q", f)
+		}
+	}
+	if *ignoreCase && !strings.Contains(flags, "i") {
+		flags += "i"
+	}
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	re, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid regular expression %s: %v", value, err)
+	}
+	return re, true, nil
+}
+
+func isGlob(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+// globToRegexp translates a glob where * and ? stop at path separators and
+// ** spans any number of directories.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + class + "]")
+				i += end
+			} else {
+				b.WriteString(`\[`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+func compileGlob(glob string, anchor string) (*regexp.Regexp, error) {
+	pattern := anchor + globToRegexp(glob) + "$"
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %s: %v", glob, err)
+	}
+	return re, nil
+}
+
+func substringMatcher(value string) textMatcher {
+	if *ignoreCase {
+		value = strings.ToLower(value)
+		return func(s string) bool { return strings.Contains(strings.ToLower(s), value) }
+	}
+	return func(s string) bool { return strings.Contains(s, value) }
+}
+
+// newTextMatcher matches titles by regular expression or substring.
+func newTextMatcher(value string) (textMatcher, error) {
+	if re, ok, err := parseRegexTerm(value); ok {
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	return substringMatcher(value), nil
+}
+
+// newExactMatcher matches whole values, such as project names.
+func newExactMatcher(value string) (textMatcher, error) {
+	if re, ok, err := parseRegexTerm(value); ok {
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	if *ignoreCase {
+		return func(s string) bool { return strings.EqualFold(s, value) }, nil
+	}
+	return func(s string) bool { return s == value }, nil
+}
+
+// newFileMatcher also accepts globs, which must match the whole path or a
+// suffix of it starting at a directory boundary.
+func newFileMatcher(value string) (textMatcher, error) {
+	if _, ok, _ := parseRegexTerm(value); ok || !isGlob(value) {
+		return newTextMatcher(value)
+	}
+	re, err := compileGlob(value, "(?:^|/)")
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// newTagMatcher matches tags normalized to their "@tag" form. Values starting
+// with @ (and all values when exact is set) must equal the whole tag, so
+// @smoke no longer matches @smoke-extended.
+func newTagMatcher(value string, exact bool) (textMatcher, error) {
+	if re, ok, err := parseRegexTerm(value); ok {
+		if err != nil {
+			return nil, err
+		}
+		return func(tag string) bool { return re.MatchString(normalizeTag(tag)) }, nil
+	}
+	if isGlob(value) {
+		re, err := compileGlob(normalizeTag(value), "^")
+		if err != nil {
+			return nil, err
+		}
+		return func(tag string) bool { return re.MatchString(normalizeTag(tag)) }, nil
+	}
+	if exact || strings.HasPrefix(value, "@") {
+		want := normalizeTag(value)
+		return func(tag string) bool {
+			if *ignoreCase {
+				return strings.EqualFold(normalizeTag(tag), want)
+			}
+			return normalizeTag(tag) == want
+		}, nil
+	}
+	contains := substringMatcher(value)
+	return func(tag string) bool { return contains(normalizeTag(tag)) }, nil
+}
+
+func normalizeTag(tag string) string {
+	return "@" + strings.TrimPrefix(tag, "@")
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+func TestNewFileMatcher(t *testing.T) {
+	cases := []struct {
+		value string
+		file  string
+		want  bool
+	}{
+		{"checkout", "tests/checkout-legacy/cart.spec.ts", true},
+		{"tests/checkout/**/*.spec.ts", "tests/checkout/cart.spec.ts", true},
+		{"tests/checkout/**/*.spec.ts", "tests/checkout/guest/pay.spec.ts", true},
+		{"tests/checkout/**/*.spec.ts", "tests/checkout-legacy/cart.spec.ts", false},
+		{"checkout/*.spec.ts", "tests/checkout/cart.spec.ts", true},
+		{"checkout/*.spec.ts", "tests/checkout/guest/pay.spec.ts", false},
+		{"cart.spec.?s", "cart.spec.ts", true},
+		{"/^tests/.*legacy/", "tests/checkout-legacy/cart.spec.ts", true},
+	}
+	for _, c := range cases {
+		match, err := newFileMatcher(c.value)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.value, err)
+		}
+		if got := match(c.file); got != c.want {
+			t.Errorf("newFileMatcher(%q)(%q) = %v, expected %v", c.value, c.file, got, c.want)
+		}
+	}
+}
+
+func TestNewTagMatcher(t *testing.T) {
+	cases := []struct {
+		value string
+		exact bool
+		tag   string
+		want  bool
+	}{
+		{"@smoke", false, "smoke", true},
+		{"@smoke", false, "@smoke-extended", false},
+		{"smoke", false, "smoke-extended", true},
+		{"smoke", true, "smoke-extended", false},
+		{"@smoke*", false, "smoke-extended", true},
+		{"/^@(smoke|sanity)$/", false, "sanity", true},
+	}
+	for _, c := range cases {
+		match, err := newTagMatcher(c.value, c.exact)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", c.value, err)
+		}
+		if got := match(c.tag); got != c.want {
+			t.Errorf("newTagMatcher(%q, %v)(%q) = %v, expected %v", c.value, c.exact, c.tag, got, c.want)
+		}
+	}
+}
+
+func TestNewTextMatcher_CaseInsensitive(t *testing.T) {
+	match, err := newTextMatcher("/^should .* todo$/i")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !match("Should add a Todo") {
+		t.Error("Expected the i flag to ignore case")
+	}
+
+	match, _ = newTextMatcher("editing")
+	if match("Editing") {
+		t.Error("Expected substring matching to be case-sensitive by default")
+	}
+
+	enabled := true
+	ignoreCase = &enabled
+	defer func() {
+		disabled := false
+		ignoreCase = &disabled
+	}()
+
+	match, _ = newTextMatcher("editing")
+	if !match("Editing") {
+		t.Error("Expected --ignore-case to apply to substrings")
+	}
+}
+
+func TestNewTextMatcher_Errors(t *testing.T) {
+	for _, value := range []string{"/(unclosed/", "/smoke/g"} {
+		if _, err := newTextMatcher(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// mergeReports combines several reports, e.g. one per Playwright config in a
+// monorepo, into one. Reports with different root directories are first
+// rebased onto their common root, so web/login.spec.ts and
+// mobile/login.spec.ts stay apart. Top-level suites are then merged by file,
+// nested suites by title and line, and specs by id, with the tests of each
+// project kept once.
+func mergeReports(reports []PlaywrightJSON) PlaywrightJSON {
+	var merged PlaywrightJSON
+	merged.Config.RootDir = commonRootDir(reports)
+	for _, report := range reports {
+		if merged.Config.RootDir != "" {
+			if prefix, err := filepath.Rel(merged.Config.RootDir, report.Config.RootDir); err == nil && prefix != "." {
+				report.Suites = rebaseSuites(report.Suites, filepath.ToSlash(prefix))
+			}
+		}
+		for _, suite := range report.Suites {
+			merged.Suites = mergeSuite(merged.Suites, suite, func(s Suite) string { return s.File })
+		}
+	}
+	return merged
+}
+
+// commonRootDir returns the deepest directory containing the root
+// directories of all reports, or "" when a report has none.
+func commonRootDir(reports []PlaywrightJSON) string {
+	var common []string
+	for i, report := range reports {
+		if report.Config.RootDir == "" {
+			return ""
+		}
+		parts := strings.Split(filepath.Clean(report.Config.RootDir), string(filepath.Separator))
+		if i == 0 {
+			common = parts
+			continue
+		}
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 1 && common[0] == "" {
+		return string(filepath.Separator)
+	}
+	return strings.Join(common, string(filepath.Separator))
+}
+
+// rebaseSuites returns copies of the suites with their files and the files of
+// their specs prefixed by the directory.
+func rebaseSuites(suites []Suite, prefix string) []Suite {
+	var rebased []Suite
+	for _, suite := range suites {
+		// File suites are titled by their file.
+		if suite.File != "" {
+			if suite.Title == suite.File {
+				suite.Title = prefix + "/" + suite.Title
+			}
+			suite.File = prefix + "/" + suite.File
+		}
+		specs := make([]Spec, len(suite.Specs))
+		for i, spec := range suite.Specs {
+			if spec.File != "" {
+				spec.File = prefix + "/" + spec.File
+			}
+			specs[i] = spec
+		}
+		suite.Specs = specs
+		suite.Suites = rebaseSuites(suite.Suites, prefix)
+		rebased = append(rebased, suite)
+	}
+	return rebased
+}
+
+func nestedSuiteKey(s Suite) string {
+	return fmt.Sprintf("%s:%d", s.Title, s.Line)
+}
+
+func specKey(s Spec) string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return fmt.Sprintf("%s:%d:%s", s.File, s.Line, s.Title)
+}
+
+func mergeSuite(suites []Suite, suite Suite, key func(Suite) string) []Suite {
+	for i := range suites {
+		if key(suites[i]) != key(suite) {
+			continue
+		}
+		existing := &suites[i]
+		for _, child := range suite.Suites {
+			existing.Suites = mergeSuite(existing.Suites, child, nestedSuiteKey)
+		}
+		for _, spec := range suite.Specs {
+			existing.Specs = mergeSpec(existing.Specs, spec)
+		}
+		return suites
+	}
+	return append(suites, suite)
+}
+
+func mergeSpec(specs []Spec, spec Spec) []Spec {
+	for i := range specs {
+		if specKey(specs[i]) != specKey(spec) {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, test := range specs[i].Tests {
+			seen[test.ProjectName] = true
+		}
+		for _, test := range spec.Tests {
+			if !seen[test.ProjectName] {
+				seen[test.ProjectName] = true
+				specs[i].Tests = append(specs[i].Tests, test)
+			}
+		}
+		return specs
+	}
+	return append(specs, spec)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeReports(t *testing.T) {
+	web := PlaywrightJSON{Config: ReportConfig{RootDir: "/repo/tests"}, Suites: []Suite{
+		{
+			Title: "login.spec.ts",
+			File:  "login.spec.ts",
+			Specs: []Spec{
+				{ID: "a1", Title: "logs in", File: "login.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium"}}},
+			},
+			Suites: []Suite{
+				{Title: "SSO", File: "login.spec.ts", Line: 10, Specs: []Spec{
+					{ID: "a2", Title: "redirects", File: "login.spec.ts", Line: 11, Tests: []TestInstance{{ProjectName: "chromium"}}},
+				}},
+			},
+		},
+	}}
+	mobile := PlaywrightJSON{Config: ReportConfig{RootDir: "/repo/tests"}, Suites: []Suite{
+		{
+			Title: "login.spec.ts",
+			File:  "login.spec.ts",
+			Specs: []Spec{
+				{ID: "a1", Title: "logs in", File: "login.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium"}, {ProjectName: "mobile-safari"}}},
+			},
+			Suites: []Suite{
+				{Title: "SSO", File: "login.spec.ts", Line: 10, Specs: []Spec{
+					{ID: "a3", Title: "shows error", File: "login.spec.ts", Line: 20, Tests: []TestInstance{{ProjectName: "mobile-safari"}}},
+				}},
+			},
+		},
+		{
+			Title: "menu.spec.ts",
+			File:  "menu.spec.ts",
+			Specs: []Spec{
+				{Title: "opens", File: "menu.spec.ts", Line: 1, Tests: []TestInstance{{ProjectName: "mobile-safari"}}},
+			},
+		},
+	}}
+
+	merged := mergeReports([]PlaywrightJSON{web, mobile})
+
+	if len(merged.Suites) != 2 {
+		t.Fatalf("Expected 2 top-level suites, got %d", len(merged.Suites))
+	}
+	login := merged.Suites[0]
+	if len(login.Specs) != 1 || len(login.Suites) != 1 || len(login.Suites[0].Specs) != 2 {
+		t.Fatalf("Expected login.spec.ts to merge specs and nested suites, got %+v", login)
+	}
+
+	var projects []string
+	for _, test := range login.Specs[0].Tests {
+		projects = append(projects, test.ProjectName)
+	}
+	if !reflect.DeepEqual(projects, []string{"chromium", "mobile-safari"}) {
+		t.Errorf("Expected projects to be unioned, got %v", projects)
+	}
+
+	if merged.Config.RootDir != "/repo/tests" {
+		t.Errorf("Expected the shared root directory to be kept, got %q", merged.Config.RootDir)
+	}
+
+	if tests, files := buildNodeTree(merged).totals(); tests != 5 || files != 2 {
+		t.Errorf("Expected 5 tests in 2 files, got %d in %d", tests, files)
+	}
+}
+
+func TestMergeReports_RootDirs(t *testing.T) {
+	report := func(rootDir string) PlaywrightJSON {
+		return PlaywrightJSON{Config: ReportConfig{RootDir: rootDir}, Suites: []Suite{
+			{Title: "login.spec.ts", File: "login.spec.ts", Suites: []Suite{
+				{Title: "SSO", File: "login.spec.ts", Line: 10, Specs: []Spec{
+					{Title: "redirects", File: "login.spec.ts", Line: 11, Tests: []TestInstance{{ProjectName: "chromium"}}},
+				}},
+			}},
+		}}
+	}
+
+	merged := mergeReports([]PlaywrightJSON{report("/repo/web"), report("/repo/mobile")})
+	if merged.Config.RootDir != "/repo" {
+		t.Errorf("Expected the common root directory, got %q", merged.Config.RootDir)
+	}
+	var files []string
+	for _, suite := range merged.Suites {
+		files = append(files, suite.File)
+	}
+	if !reflect.DeepEqual(files, []string{"web/login.spec.ts", "mobile/login.spec.ts"}) {
+		t.Fatalf("Expected two distinct files, got %v", files)
+	}
+	if spec := merged.Suites[1].Suites[0].Specs[0]; spec.File != "mobile/login.spec.ts" {
+		t.Errorf("Expected specs to be rebased, got %q", spec.File)
+	}
+	if tests, files := buildNodeTree(merged).totals(); tests != 2 || files != 2 {
+		t.Errorf("Expected 2 tests in 2 files, got %d in %d", tests, files)
+	}
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"web.json", "admin.json", "notes.txt"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(inputFixture), 0644)
+	}
+
+	paths, err := expandPaths([]string{filepath.Join(dir, "*.json"), "-"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "admin.json"), filepath.Join(dir, "web.json"), "-"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	shard := filepath.Join(dir, "[shard-1]", "report.json")
+	os.Mkdir(filepath.Dir(shard), 0755)
+	os.WriteFile(shard, []byte(inputFixture), 0644)
+	if paths, err := expandPaths([]string{shard}); err != nil || !reflect.DeepEqual(paths, []string{shard}) {
+		t.Errorf("Expected an existing path with brackets to be kept, got %v, %v", paths, err)
+	}
+
+	if _, err := expandPaths([]string{filepath.Join(dir, "*.gz")}); err == nil {
+		t.Error("Expected an error for a pattern without matches")
+	}
+}
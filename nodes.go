@@ -0,0 +1,519 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type nodeKind int
+
+const (
+	rootNode nodeKind = iota
+	configNode
+	groupNode
+	dirNode
+	fileNode
+	suiteNode
+	specNode
+	resultNode
+	detailNode
+)
+
+type aggSpec struct {
+	Title    string
+	File     string
+	Line     int
+	Tags     map[string]bool
+	Projects map[string]bool
+	Skipped  bool
+	Fixme    bool
+	Fail     bool
+	Outcomes map[string]*testOutcome
+	Results  map[string][]TestResult
+}
+
+// treeNode is the normalized, render-agnostic form of the suite hierarchy:
+// files contain suites, suites contain suites and aggregated specs.
+type treeNode struct {
+	Kind     nodeKind
+	Group    string
+	Detail   string
+	Status   string
+	Duration time.Duration
+	Heat     string
+	Title    string
+	File     string
+	Line     int
+	Spec     *aggSpec
+	Change   *specChange
+	Children []*treeNode
+}
+
+func (n *treeNode) testCount() int {
+	if n.Kind == specNode {
+		return len(n.Spec.Projects)
+	}
+	total := 0
+	for _, child := range n.Children {
+		total += child.testCount()
+	}
+	return total
+}
+
+// specCount counts distinct specs, like totals.
+func (n *treeNode) specCount() int {
+	specs := map[string]bool{}
+	var walk func(n *treeNode, scope string)
+	walk = func(n *treeNode, scope string) {
+		if n.Kind == configNode {
+			scope = n.Title
+		}
+		if n.Kind == specNode {
+			specs[fmt.Sprintf("%s|%s:%d:%s", scope, n.Spec.File, n.Spec.Line, n.Spec.Title)] = true
+		}
+		for _, child := range n.Children {
+			walk(child, scope)
+		}
+	}
+	walk(n, "")
+	return len(specs)
+}
+
+// totals counts distinct tests (spec and project pairs) and files, so specs
+// listed under several groups are only counted once. Files of different
+// workspace configs are counted separately even when their paths match.
+func (n *treeNode) totals() (int, int) {
+	tests := map[string]bool{}
+	files := map[string]bool{}
+
+	var walk func(n *treeNode, scope string)
+	walk = func(n *treeNode, scope string) {
+		if n.Kind == configNode {
+			scope = n.Title
+		}
+		if n.Kind == specNode {
+			files[scope+"|"+n.Spec.File] = true
+			for project := range n.Spec.Projects {
+				tests[fmt.Sprintf("%s|%s:%d:%s|%s", scope, n.Spec.File, n.Spec.Line, n.Spec.Title, project)] = true
+			}
+		}
+		for _, child := range n.Children {
+			walk(child, scope)
+		}
+	}
+	walk(n, "")
+
+	return len(tests), len(files)
+}
+
+// aggregateSpecs merges the per-project entries of each spec, keeping the
+// order in which specs first appear.
+func aggregateSpecs(specs []Spec) []*aggSpec {
+	aggSpecs := map[string]*aggSpec{}
+	var ordered []*aggSpec
+
+	for _, spec := range specs {
+		key := fmt.Sprintf("%s:%d:%s", spec.File, spec.Line, spec.Title)
+		as, exists := aggSpecs[key]
+		if !exists {
+			as = &aggSpec{
+				Title:    spec.Title,
+				File:     spec.File,
+				Line:     spec.Line,
+				Tags:     map[string]bool{},
+				Projects: map[string]bool{},
+				Outcomes: map[string]*testOutcome{},
+				Results:  map[string][]TestResult{},
+			}
+			aggSpecs[key] = as
+			ordered = append(ordered, as)
+		}
+		for _, tag := range spec.Tags {
+			as.Tags[tag] = true
+		}
+		for _, test := range spec.Tests {
+			as.Projects[test.ProjectName] = true
+			if outcome := outcomeOf(test); outcome != nil {
+				as.Outcomes[test.ProjectName] = outcome
+				as.Results[test.ProjectName] = test.Results
+			}
+			for _, ann := range test.Annotations {
+				switch ann.Type {
+				case "skip":
+					as.Skipped = true
+				case "fixme":
+					as.Fixme = true
+				case "fail":
+					as.Fail = true
+				}
+			}
+		}
+	}
+
+	return ordered
+}
+
+func buildNodeTree(pwData PlaywrightJSON) *treeNode {
+	root := &treeNode{Kind: rootNode}
+	seenTests := map[string]bool{}
+
+	var processSuite func(suite Suite, parent *treeNode, parentFile string, describes []string) bool
+
+	processSuite = func(suite Suite, parent *treeNode, parentFile string, describes []string) bool {
+		currentFile := suite.File
+		if currentFile == "" {
+			currentFile = parentFile
+		}
+
+		node := parent
+		if suite.Title != "" && suite.Title != suite.File {
+			describes = append(slices.Clone(describes), suite.Title)
+			node = &treeNode{
+				Kind:  suiteNode,
+				Title: suite.Title,
+				File:  currentFile,
+				Line:  suiteLine(suite),
+			}
+		}
+
+		var hasVisibleSpecs bool
+
+		for _, as := range aggregateSpecs(suite.Specs) {
+			showAny := *showSkipped || *showFixme || *showFail
+			matchesAnnotation := (!showAny) ||
+				(*showSkipped && as.Skipped) ||
+				(*showFixme && as.Fixme) ||
+				(*showFail && as.Fail)
+
+			if !matchesAnnotation {
+				continue
+			}
+
+			// Lockfiles have no lines, so the describe titles tell apart
+			// specs sharing a title in one file.
+			key := fmt.Sprintf("%s:%d:%s", as.File, as.Line, strings.Join(append(slices.Clone(describes), as.Title), " › "))
+			if seenTests[key] {
+				continue
+			}
+			seenTests[key] = true
+			hasVisibleSpecs = true
+
+			node.Children = append(node.Children, &treeNode{
+				Kind:  specNode,
+				Title: as.Title,
+				File:  as.File,
+				Line:  as.Line,
+				Spec:  as,
+			})
+		}
+
+		var hasVisibleChildren bool
+		for _, child := range suite.Suites {
+			if processSuite(child, node, currentFile, describes) {
+				hasVisibleChildren = true
+			}
+		}
+
+		if !hasVisibleSpecs && !hasVisibleChildren {
+			return false
+		}
+		if node != parent {
+			parent.Children = append(parent.Children, node)
+		}
+		return true
+	}
+
+	for _, topSuite := range pwData.Suites {
+		if topSuite.File == "" {
+			continue
+		}
+		file := &treeNode{Kind: fileNode, Title: topSuite.File, File: topSuite.File}
+		if processSuite(topSuite, file, topSuite.File, nil) {
+			root.Children = append(root.Children, file)
+		}
+	}
+
+	sortNodes(root, sortBy)
+
+	if groupBy != "file" && groupBy != "" {
+		root = pivotNodes(root, groupBy)
+	}
+
+	if *groupByDir {
+		parents := []*treeNode{root}
+		if root.Children != nil && root.Children[0].Kind == groupNode {
+			parents = root.Children
+		}
+		for _, parent := range parents {
+			parent.Children = groupByDirectory(parent, *compactDirs).Children
+			sortNodes(parent, sortBy)
+		}
+	}
+
+	rollUpDurations(root)
+	if *heatmap {
+		applyHeatmap(root, heatmapGradient)
+	}
+
+	if *showErrors || *showSteps || *showAttachments {
+		attachResults(root, pwData.Config.RootDir)
+	}
+
+	return root
+}
+
+var groupByModes = []string{"file", "tag", "project", "annotation"}
+
+// annotationGroups are the --group-by annotation buckets, in display order.
+var annotationGroups = []struct {
+	title string
+	match func(as *aggSpec) bool
+}{
+	{"skipped", func(as *aggSpec) bool { return as.Skipped }},
+	{"fixme", func(as *aggSpec) bool { return as.Fixme }},
+	{"fail", func(as *aggSpec) bool { return as.Fail }},
+	{"no annotation", func(as *aggSpec) bool { return !as.Skipped && !as.Fixme && !as.Fail }},
+}
+
+// pivotNodes replaces the root's children with one group per tag, project or
+// annotation, each holding the file and suite hierarchy of its specs.
+func pivotNodes(root *treeNode, mode string) *treeNode {
+	tagSet := map[string]bool{}
+	projectSet := map[string]bool{}
+	var collect func(n *treeNode)
+	collect = func(n *treeNode) {
+		if n.Spec != nil {
+			for tag := range n.Spec.Tags {
+				tagSet[tag] = true
+			}
+			for project := range n.Spec.Projects {
+				projectSet[project] = true
+			}
+		}
+		for _, child := range n.Children {
+			collect(child)
+		}
+	}
+	collect(root)
+
+	pivoted := &treeNode{Kind: rootNode}
+	addGroup := func(title string, keep func(as *aggSpec) *aggSpec) {
+		group := &treeNode{Kind: groupNode, Group: mode, Title: title}
+		for _, child := range root.Children {
+			if kept := pruneNodes(child, keep); kept != nil {
+				group.Children = append(group.Children, kept)
+			}
+		}
+		if len(group.Children) > 0 {
+			pivoted.Children = append(pivoted.Children, group)
+		}
+	}
+
+	switch mode {
+	case "tag":
+		for _, tag := range sortedKeys(tagSet) {
+			addGroup(tag, func(as *aggSpec) *aggSpec {
+				if as.Tags[tag] {
+					return as
+				}
+				return nil
+			})
+		}
+		addGroup("untagged", func(as *aggSpec) *aggSpec {
+			if len(as.Tags) == 0 {
+				return as
+			}
+			return nil
+		})
+	case "project":
+		for _, project := range sortedKeys(projectSet) {
+			addGroup(project, func(as *aggSpec) *aggSpec {
+				if !as.Projects[project] {
+					return nil
+				}
+				restricted := *as
+				restricted.Projects = map[string]bool{project: true}
+				return &restricted
+			})
+		}
+	case "annotation":
+		for _, ag := range annotationGroups {
+			addGroup(ag.title, func(as *aggSpec) *aggSpec {
+				if ag.match(as) {
+					return as
+				}
+				return nil
+			})
+		}
+	}
+
+	return pivoted
+}
+
+// pruneNodes copies the subtree, keeping the specs for which keep returns a
+// spec and dropping suites and files left empty.
+func pruneNodes(n *treeNode, keep func(as *aggSpec) *aggSpec) *treeNode {
+	if n.Kind == specNode {
+		as := keep(n.Spec)
+		if as == nil {
+			return nil
+		}
+		pruned := *n
+		pruned.Spec = as
+		return &pruned
+	}
+
+	pruned := *n
+	pruned.Children = nil
+	for _, child := range n.Children {
+		if kept := pruneNodes(child, keep); kept != nil {
+			pruned.Children = append(pruned.Children, kept)
+		}
+	}
+	if len(pruned.Children) == 0 {
+		return nil
+	}
+	return &pruned
+}
+
+// groupByDirectory nests the file nodes under one node per directory of
+// their path, optionally merging directories that have a single child.
+func groupByDirectory(root *treeNode, compact bool) *treeNode {
+	grouped := &treeNode{Kind: rootNode}
+	dirs := map[string]*treeNode{"": grouped}
+
+	var dirFor func(dir string) *treeNode
+	dirFor = func(dir string) *treeNode {
+		if node, ok := dirs[dir]; ok {
+			return node
+		}
+		node := &treeNode{Kind: dirNode, Title: path.Base(dir), File: dir}
+		parent := dirFor(parentDir(dir))
+		parent.Children = append(parent.Children, node)
+		dirs[dir] = node
+		return node
+	}
+
+	for _, file := range root.Children {
+		filePath := filepath.ToSlash(file.File)
+		file.Title = path.Base(filePath)
+		parent := dirFor(parentDir(filePath))
+		parent.Children = append(parent.Children, file)
+	}
+
+	if compact {
+		compactDirectories(grouped)
+	}
+	return grouped
+}
+
+func parentDir(p string) string {
+	dir := path.Dir(p)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+func compactDirectories(n *treeNode) {
+	for i, child := range n.Children {
+		for child.Kind == dirNode && len(child.Children) == 1 {
+			only := child.Children[0]
+			only.Title = child.Title + "/" + only.Title
+			child = only
+		}
+		n.Children[i] = child
+		if child.Kind == dirNode {
+			compactDirectories(child)
+		}
+	}
+}
+
+var sortModes = []string{"line", "title", "tag", "projects", "annotation"}
+
+// sortNodes orders siblings at every level. Ties, and the "line" mode, fall
+// back to source order: line first, then title (which orders files by path).
+func sortNodes(n *treeNode, mode string) {
+	if n.Kind == specNode {
+		return
+	}
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		switch mode {
+		case "title":
+			if a.Title != b.Title {
+				return a.Title < b.Title
+			}
+		case "tag":
+			ta, tb := firstTag(a), firstTag(b)
+			if ta != tb {
+				// Untagged nodes go last.
+				if ta == "" || tb == "" {
+					return tb == ""
+				}
+				return ta < tb
+			}
+		case "projects":
+			if ca, cb := a.testCount(), b.testCount(); ca != cb {
+				return ca > cb
+			}
+		case "annotation":
+			if ra, rb := annotationRank(a), annotationRank(b); ra != rb {
+				return ra < rb
+			}
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Title < b.Title
+	})
+
+	for _, child := range n.Children {
+		sortNodes(child, mode)
+	}
+}
+
+// firstTag returns the alphabetically first tag of the node's specs.
+func firstTag(n *treeNode) string {
+	first := ""
+	if n.Spec != nil {
+		for tag := range n.Spec.Tags {
+			if first == "" || tag < first {
+				first = tag
+			}
+		}
+	}
+	for _, child := range n.Children {
+		if tag := firstTag(child); tag != "" && (first == "" || tag < first) {
+			first = tag
+		}
+	}
+	return first
+}
+
+// annotationRank orders skipped before fixme before fail before plain specs;
+// suites and files take the lowest rank of their specs.
+func annotationRank(n *treeNode) int {
+	rank := 3
+	if n.Spec != nil {
+		switch {
+		case n.Spec.Skipped:
+			rank = 0
+		case n.Spec.Fixme:
+			rank = 1
+		case n.Spec.Fail:
+			rank = 2
+		}
+	}
+	for _, child := range n.Children {
+		if r := annotationRank(child); r < rank {
+			rank = r
+		}
+	}
+	return rank
+}
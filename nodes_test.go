@@ -0,0 +1,157 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func sortFixture() PlaywrightJSON {
+	return PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "b.spec.ts",
+				File:  "b.spec.ts",
+				Specs: []Spec{
+					{Title: "zeta", File: "b.spec.ts", Line: 9, Tests: []TestInstance{{ProjectName: "chromium"}}},
+					{Title: "alpha", File: "b.spec.ts", Line: 20, Tags: []string{"smoke"}, Tests: []TestInstance{{ProjectName: "chromium"}, {ProjectName: "webkit"}}},
+					{Title: "mid", File: "b.spec.ts", Line: 3, Tests: []TestInstance{{ProjectName: "chromium", Annotations: []Annotation{{Type: "fixme"}}}}},
+				},
+				Suites: []Suite{
+					{
+						Title: "Group",
+						File:  "b.spec.ts",
+						Line:  12,
+						Specs: []Spec{
+							{Title: "nested", File: "b.spec.ts", Line: 13, Tags: []string{"auth"}, Tests: []TestInstance{{ProjectName: "chromium"}}},
+						},
+					},
+				},
+			},
+			{
+				Title: "a.spec.ts",
+				File:  "a.spec.ts",
+				Specs: []Spec{
+					{Title: "only", File: "a.spec.ts", Line: 1, Tests: []TestInstance{{ProjectName: "chromium"}}},
+				},
+			},
+		},
+	}
+}
+
+func childTitles(n *treeNode) []string {
+	var titles []string
+	for _, child := range n.Children {
+		titles = append(titles, child.Title)
+	}
+	return titles
+}
+
+func TestSortNodes(t *testing.T) {
+	original := sortBy
+	defer func() { sortBy = original }()
+
+	cases := []struct {
+		mode  string
+		files []string
+		specs []string
+	}{
+		{"line", []string{"a.spec.ts", "b.spec.ts"}, []string{"mid", "zeta", "Group", "alpha"}},
+		{"title", []string{"a.spec.ts", "b.spec.ts"}, []string{"Group", "alpha", "mid", "zeta"}},
+		{"tag", []string{"b.spec.ts", "a.spec.ts"}, []string{"Group", "alpha", "mid", "zeta"}},
+		{"projects", []string{"b.spec.ts", "a.spec.ts"}, []string{"alpha", "mid", "zeta", "Group"}},
+		{"annotation", []string{"b.spec.ts", "a.spec.ts"}, []string{"mid", "zeta", "Group", "alpha"}},
+	}
+	for _, c := range cases {
+		sortBy = c.mode
+		root := buildNodeTree(sortFixture())
+		if got := childTitles(root); !reflect.DeepEqual(got, c.files) {
+			t.Errorf("--sort %s: expected files %v, got %v", c.mode, c.files, got)
+		}
+		for _, file := range root.Children {
+			if file.Title != "b.spec.ts" {
+				continue
+			}
+			if got := childTitles(file); !reflect.DeepEqual(got, c.specs) {
+				t.Errorf("--sort %s: expected %v, got %v", c.mode, c.specs, got)
+			}
+		}
+	}
+}
+
+func TestGroupByDirectory(t *testing.T) {
+	fileNodeAt := func(file string) *treeNode {
+		return &treeNode{
+			Kind:  fileNode,
+			Title: file,
+			File:  file,
+			Children: []*treeNode{
+				{Kind: specNode, Title: "test", File: file, Spec: &aggSpec{Title: "test", File: file, Projects: map[string]bool{"chromium": true}}},
+			},
+		}
+	}
+	build := func() *treeNode {
+		return &treeNode{Kind: rootNode, Children: []*treeNode{
+			fileNodeAt("tests/checkout/cart.spec.ts"),
+			fileNodeAt("tests/checkout/pay.spec.ts"),
+			fileNodeAt("tests/admin/users/list.spec.ts"),
+			fileNodeAt("root.spec.ts"),
+		}}
+	}
+
+	grouped := groupByDirectory(build(), false)
+	if got := childTitles(grouped); !reflect.DeepEqual(got, []string{"tests", "root.spec.ts"}) {
+		t.Fatalf("Expected top-level directory and root file, got %v", got)
+	}
+	tests := grouped.Children[0]
+	if totalTests, totalFiles := tests.totals(); tests.Kind != dirNode || totalTests != 3 || totalFiles != 3 {
+		t.Errorf("Expected tests/ to hold 3 tests in 3 files, got %d in %d", totalTests, totalFiles)
+	}
+	if got := childTitles(tests); !reflect.DeepEqual(got, []string{"checkout", "admin"}) {
+		t.Errorf("Unexpected subdirectories %v", got)
+	}
+	if got := childTitles(tests.Children[0]); !reflect.DeepEqual(got, []string{"cart.spec.ts", "pay.spec.ts"}) {
+		t.Errorf("Expected file nodes titled by base name, got %v", got)
+	}
+	if tests.Children[0].Children[0].File != "tests/checkout/cart.spec.ts" {
+		t.Errorf("Expected file nodes to keep their full path")
+	}
+
+	compacted := groupByDirectory(build(), true)
+	tests = compacted.Children[0]
+	if got := childTitles(tests); !reflect.DeepEqual(got, []string{"checkout", "admin/users/list.spec.ts"}) {
+		t.Errorf("Expected single-child chains to be merged, got %v", got)
+	}
+	if got := compacted.Children[1]; got.Kind != fileNode || got.Title != "root.spec.ts" {
+		t.Errorf("Expected the root file to be left alone, got %+v", got)
+	}
+}
+
+func TestPivotNodes(t *testing.T) {
+	original := groupBy
+	defer func() { groupBy = original }()
+
+	cases := []struct {
+		mode   string
+		groups []string
+		tests  []int
+	}{
+		{"tag", []string{"auth", "smoke", "untagged"}, []int{1, 2, 3}},
+		{"project", []string{"chromium", "webkit"}, []int{5, 1}},
+		{"annotation", []string{"fixme", "no annotation"}, []int{1, 5}},
+	}
+	for _, c := range cases {
+		groupBy = c.mode
+		root := buildNodeTree(sortFixture())
+		if got := childTitles(root); !reflect.DeepEqual(got, c.groups) {
+			t.Fatalf("--group-by %s: expected groups %v, got %v", c.mode, c.groups, got)
+		}
+		for i, group := range root.Children {
+			if group.Kind != groupNode || group.testCount() != c.tests[i] {
+				t.Errorf("--group-by %s: expected %s to hold %d tests, got %d", c.mode, group.Title, c.tests[i], group.testCount())
+			}
+		}
+		if tests, files := root.totals(); tests != 6 || files != 2 {
+			t.Errorf("--group-by %s: expected totals of 6 tests in 2 files, got %d in %d", c.mode, tests, files)
+		}
+	}
+}
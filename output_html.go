@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// htmlStyleNames are the style entries that have a CSS counterpart in the export.
+var htmlStyleNames = []string{
+	"root", "dir", "file", "suite", "test", "tag", "project", "fileLine",
+	"skipped", "fixme", "fail", "passed", "failed", "flaky", "counter", "enumerator",
+}
+
+// ansiColors are the xterm defaults for the 16 basic ANSI colors.
+var ansiColors = []string{
+	"#000000", "#800000", "#008000", "#808000", "#000080", "#800080", "#008080", "#c0c0c0",
+	"#808080", "#ff0000", "#00ff00", "#ffff00", "#0000ff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// cssColor converts a lipgloss color (ANSI index or hex) to a CSS color.
+func cssColor(color string) string {
+	if strings.HasPrefix(color, "#") {
+		return color
+	}
+	n, err := strconv.Atoi(color)
+	if err != nil || n < 0 || n > 255 {
+		return ""
+	}
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		levels := []int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[(n/6)%6], levels[n%6])
+	default:
+		gray := 8 + 10*(n-232)
+		return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+	}
+}
+
+// htmlThemeCSS maps the configured style palette to CSS rules so the export
+// matches the terminal theme.
+func htmlThemeCSS(styles map[string]lipgloss.Style) string {
+	var b strings.Builder
+	for _, name := range htmlStyleNames {
+		style, ok := styles[name]
+		if !ok {
+			continue
+		}
+		var rules []string
+		if color, ok := style.GetForeground().(lipgloss.Color); ok {
+			if css := cssColor(string(color)); css != "" {
+				rules = append(rules, "color: "+css)
+			}
+		}
+		if style.GetBold() {
+			rules = append(rules, "font-weight: bold")
+		}
+		if style.GetItalic() {
+			rules = append(rules, "font-style: italic")
+		}
+		if style.GetFaint() {
+			rules = append(rules, "opacity: 0.7")
+		}
+		if len(rules) > 0 {
+			fmt.Fprintf(&b, ".s-%s { %s; }\n", name, strings.Join(rules, "; "))
+		}
+	}
+	return b.String()
+}
+
+type htmlExport struct {
+	Title  string
+	Theme  template.CSS
+	Data   template.JS
+	Emojis DisplayEmojis
+}
+
+func buildHTMLExport(pwData PlaywrightJSON, styles map[string]lipgloss.Style, emojis DisplayEmojis) (string, error) {
+	return renderHTMLExport(buildNodeTree(pwData), styles, emojis)
+}
+
+func renderHTMLExport(nodes *treeNode, styles map[string]lipgloss.Style, emojis DisplayEmojis) (string, error) {
+	data, err := json.Marshal(jsonTree(nodes))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	err = htmlTemplate.Execute(&b, htmlExport{
+		Title:  strings.TrimSpace(emojis.Root + " Playwright-tree"),
+		Theme:  template.CSS(htmlThemeCSS(styles)),
+		Data:   template.JS(data),
+		Emojis: emojis,
+	})
+	return b.String(), err
+}
+
+var htmlTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { background: #1e1e1e; color: #d4d4d4; font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; margin: 2em; }
+h1 { font-size: 1.4em; margin: 0 0 .5em; }
+#toolbar { display: flex; gap: .5em; align-items: center; flex-wrap: wrap; margin-bottom: .5em; }
+#search { flex: 1; min-width: 20em; padding: .4em .6em; background: #2d2d2d; color: inherit; border: 1px solid #555; border-radius: 4px; font: inherit; }
+button { background: #2d2d2d; color: inherit; border: 1px solid #555; border-radius: 4px; padding: .4em .8em; font: inherit; cursor: pointer; }
+.chips { display: flex; gap: .3em; flex-wrap: wrap; margin: .3em 0; }
+.chip { border: 1px solid #555; border-radius: 1em; padding: 0 .6em; cursor: pointer; user-select: none; }
+.chip.active { background: #444; border-color: #aaa; }
+ul { list-style: none; margin: 0; padding-left: 1.4em; border-left: 1px solid #444; }
+#tree > ul { padding-left: 0; border-left: none; }
+summary { cursor: pointer; }
+.count, .s-fileLine { opacity: .7; }
+.badge { font-size: .85em; }
+pre { display: inline-block; vertical-align: top; margin: 0; font: inherit; white-space: pre-wrap; }
+{{.Theme}}
+</style>
+</head>
+<body>
+<h1 class="s-root">{{.Title}}</h1>
+<div id="toolbar">
+  <input id="search" type="search" placeholder="Search titles, files, tags and projects" autofocus>
+  <button id="expand">Unfold all</button>
+  <button id="collapse">Fold all</button>
+</div>
+<div class="chips" id="tags"></div>
+<div class="chips" id="projects"></div>
+<p id="counter" class="s-counter"></p>
+<div id="tree"></div>
+<script>
+const data = {{.Data}};
+const emojis = { dir: {{.Emojis.Dir}}, file: {{.Emojis.File}}, suite: {{.Emojis.Suite}} };
+const active = { tags: new Set(), projects: new Set() };
+
+function el(tag, cls, text) {
+  const e = document.createElement(tag);
+  if (cls) e.className = cls;
+  if (text !== undefined) e.textContent = text;
+  return e;
+}
+
+function plural(n) { return n === 1 ? "" : "s"; }
+
+function specMatches(spec, query) {
+  for (const t of active.tags) if (!spec.tags.includes(t)) return false;
+  for (const p of active.projects) if (!spec.projects.includes(p)) return false;
+  if (!query) return true;
+  const text = [spec.title, spec.file, ...spec.tags, ...spec.projects].join(" ").toLowerCase();
+  return text.includes(query);
+}
+
+// filter returns a copy of the node restricted to matching specs, or null.
+function filter(node, query, ancestorMatched) {
+  if (node.type === "spec") return specMatches(node, ancestorMatched ? "" : query) ? node : null;
+  const matched = ancestorMatched || (query !== "" && node.title.toLowerCase().includes(query));
+  const children = (node.children || []).map(c => filter(c, query, matched)).filter(Boolean);
+  if (children.length === 0) return null;
+  const tests = children.reduce((sum, c) => sum + c.tests, 0);
+  return Object.assign({}, node, { children, tests });
+}
+
+const outcomeRanks = { failed: 0, timedOut: 1, interrupted: 2, flaky: 3, passed: 4, skipped: 5 };
+
+// outcomeClass styles a spec by its worst project outcome in a test run.
+function outcomeClass(node) {
+  const statuses = Object.values(node.results || {}).map(r => r.status);
+  if (!statuses.length) return "";
+  const worst = statuses.sort((a, b) => outcomeRanks[a] - outcomeRanks[b])[0];
+  return "s-" + (worst === "timedOut" || worst === "interrupted" ? "failed" : worst);
+}
+
+function formatDuration(ms) {
+  return ms < 1000 ? ms + "ms" : (ms / 1000).toFixed(1) + "s";
+}
+
+function formatResult(r) {
+  if (r.status === "skipped") return r.status;
+  const duration = formatDuration(r.durationMs);
+  const retries = r.retries === 1 ? " after 1 retry" : r.retries > 1 ? " after " + r.retries + " retries" : "";
+  return r.status + " " + duration + retries;
+}
+
+function renderSpec(node) {
+  const li = el("li");
+  const cls = outcomeClass(node) || (node.skipped ? "s-skipped" : node.fixme ? "s-fixme" : node.fail ? "s-fail" : "s-test");
+  li.appendChild(el("span", cls, node.title));
+  for (const flag of ["skipped", "fixme", "fail"]) {
+    if (node[flag]) li.appendChild(el("span", "badge s-" + flag, " [" + flag + "]"));
+  }
+  const results = node.results || {};
+  if (node.projects.length) li.appendChild(el("span", "s-project", " (" + node.projects.map(p => results[p] ? p + ": " + formatResult(results[p]) : p).join(", ") + ")"));
+  if (node.tags.length) li.appendChild(el("span", "s-tag", " [" + node.tags.join(", ") + "]"));
+  li.appendChild(el("span", "s-fileLine", " (" + node.file + ":" + node.line + ")"));
+  if (node.children && node.children.length) {
+    const ul = el("ul");
+    for (const child of node.children) ul.appendChild(renderDetail(child));
+    li.appendChild(ul);
+  }
+  return li;
+}
+
+// renderDetail renders the results of a run beneath their spec.
+function renderDetail(node) {
+  const li = el("li");
+  let label;
+  if (node.type === "result") label = el("span", "s-" + (node.status === "timedOut" || node.status === "interrupted" ? "failed" : node.status), node.title);
+  else if (node.type === "location") label = el("span", "s-fileLine", node.title);
+  else if (node.type === "attachment") {
+    label = el("span", "s-test", node.title);
+    label.appendChild(el("span", "s-fileLine", " " + (node.file || "(inline)")));
+  }
+  else if (node.type === "step") {
+    label = el("span");
+    label.appendChild(el("span", node.status === "failed" ? "s-failed" : "s-test", node.title));
+    label.appendChild(el("span", "count", " " + formatDuration(node.durationMs)));
+  }
+  else label = el("pre", node.type === "error" ? "s-failed" : "", node.title);
+  if (!node.children || !node.children.length) {
+    li.appendChild(label);
+    return li;
+  }
+  const details = el("details");
+  details.open = true;
+  const summary = el("summary");
+  summary.appendChild(label);
+  details.appendChild(summary);
+  const ul = el("ul");
+  for (const child of node.children) ul.appendChild(renderDetail(child));
+  details.appendChild(ul);
+  li.appendChild(details);
+  return li;
+}
+
+function renderNode(node) {
+  if (node.type === "spec") return renderSpec(node);
+  const li = el("li");
+  const details = el("details");
+  details.open = true;
+  const summary = el("summary");
+  const emoji = emojis[node.type];
+  const title = node.type === "dir" ? node.title + "/" : node.title;
+  summary.appendChild(el("span", "s-" + node.type, (emoji ? emoji + " " : "") + title));
+  if (node.type === "suite") summary.appendChild(el("span", "s-fileLine", " (" + node.file + ":" + node.line + ")"));
+  summary.appendChild(el("span", "count", " " + node.tests + " test" + plural(node.tests)));
+  details.appendChild(summary);
+  const ul = el("ul");
+  for (const child of node.children) ul.appendChild(renderNode(child));
+  details.appendChild(ul);
+  li.appendChild(details);
+  return li;
+}
+
+// totals counts distinct tests and files, as specs can appear in several groups.
+function totals(nodes) {
+  const tests = new Set(), files = new Set();
+  (function walk(n, scope) {
+    if (n.type === "config") scope = n.title;
+    if (n.type === "spec") {
+      files.add(scope + "|" + n.file);
+      n.projects.forEach(p => tests.add(scope + "|" + n.file + ":" + n.line + ":" + n.title + "|" + p));
+    }
+    (n.children || []).forEach(c => walk(c, scope));
+  })({ children: nodes }, "");
+  return [tests.size, files.size];
+}
+
+function render() {
+  const query = document.getElementById("search").value.trim().toLowerCase();
+  const nodes = data.children.map(c => filter(c, query, false)).filter(Boolean);
+  const [tests, files] = totals(nodes);
+  document.getElementById("counter").textContent =
+    "Total: " + tests + " test" + plural(tests) + " in " + files + " file" + plural(files);
+  const ul = el("ul");
+  for (const node of nodes) ul.appendChild(renderNode(node));
+  const tree = document.getElementById("tree");
+  tree.replaceChildren(ul);
+}
+
+function collect(key) {
+  const set = new Set();
+  (function walk(n) {
+    if (n.type === "spec") n[key].forEach(v => set.add(v));
+    (n.children || []).forEach(walk);
+  })(data);
+  return [...set].sort();
+}
+
+function renderChips(id, key) {
+  const container = document.getElementById(id);
+  for (const value of collect(key)) {
+    const chip = el("span", "chip s-" + (key === "tags" ? "tag" : "project"), value);
+    chip.addEventListener("click", () => {
+      active[key].has(value) ? active[key].delete(value) : active[key].add(value);
+      chip.classList.toggle("active");
+      render();
+    });
+    container.appendChild(chip);
+  }
+}
+
+function setOpen(open) {
+  document.querySelectorAll("#tree details").forEach(d => { d.open = open; });
+}
+
+renderChips("tags", "tags");
+renderChips("projects", "projects");
+document.getElementById("search").addEventListener("input", render);
+document.getElementById("expand").addEventListener("click", () => setOpen(true));
+document.getElementById("collapse").addEventListener("click", () => setOpen(false));
+render();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestCSSColor(t *testing.T) {
+	cases := map[string]string{
+		"#ff8800": "#ff8800",
+		"7":       "#c0c0c0",
+		"150":     "#afd787",
+		"244":     "#808080",
+		"":        "",
+		"purple":  "",
+	}
+	for input, expected := range cases {
+		if got := cssColor(input); got != expected {
+			t.Errorf("cssColor(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestBuildHTMLExport(t *testing.T) {
+	pwData := PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "cart.spec.ts",
+				File:  "cart.spec.ts",
+				Specs: []Spec{
+					{Title: "adds </script> item", File: "cart.spec.ts", Line: 4, Tests: []TestInstance{{ProjectName: "chromium"}}},
+				},
+			},
+		},
+	}
+	styles := map[string]lipgloss.Style{
+		"file": lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true),
+	}
+
+	page, err := buildHTMLExport(pwData, styles, DisplayEmojis{Root: "🎭"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(page, "<title>🎭 Playwright-tree</title>") {
+		t.Errorf("Expected the root title in the page")
+	}
+	if !strings.Contains(page, ".s-file { color: #008080; font-weight: bold; }") {
+		t.Errorf("Expected the file style to be mapped to CSS\nGot:\n%s", page)
+	}
+	if strings.Count(page, "</script>") != 1 {
+		t.Errorf("Expected test titles to be escaped inside the embedded data")
+	}
+	if !strings.Contains(page, `"totalTests":1`) {
+		t.Errorf("Expected the tree data to be embedded")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+// jsonSchemaVersion is bumped whenever a field of the --format json output
+// is removed or changes meaning. Adding fields does not bump it.
+const jsonSchemaVersion = 1
+
+type JSONTree struct {
+	Version    int `json:"version"`
+	TotalTests int `json:"totalTests"`
+	TotalFiles int `json:"totalFiles"`
+	// Summary counts the changes of each category in `pwtree diff` output.
+	Summary  []string   `json:"summary,omitempty"`
+	Children []JSONNode `json:"children"`
+}
+
+type JSONNode struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	File  string `json:"file,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Tests int    `json:"tests"`
+	// Status is the outcome of result nodes, e.g. "failed".
+	Status string `json:"status,omitempty"`
+	// Duration is the time taken by the tests of run reports, retries
+	// included, or by a result or step.
+	Duration int64 `json:"durationMs,omitempty"`
+	// Change is "added", "removed" or "changed" for the specs of a diff,
+	// with Changes describing what changed, e.g. "tags +smoke".
+	Change  string   `json:"change,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+	*JSONSpec
+	Children []JSONNode `json:"children,omitempty"`
+}
+
+type JSONSpec struct {
+	Tags     []string `json:"tags"`
+	Projects []string `json:"projects"`
+	Skipped  bool     `json:"skipped"`
+	Fixme    bool     `json:"fixme"`
+	Fail     bool     `json:"fail"`
+	// Results is keyed by project and only present for reports of test runs.
+	Results map[string]JSONResult `json:"results,omitempty"`
+}
+
+type JSONResult struct {
+	Status   string `json:"status"`
+	Retries  int    `json:"retries"`
+	Duration int64  `json:"durationMs"`
+}
+
+var nodeKindNames = map[nodeKind]string{
+	rootNode:   "root",
+	dirNode:    "dir",
+	fileNode:   "file",
+	configNode: "config",
+	suiteNode:  "suite",
+	specNode:   "spec",
+	resultNode: "result",
+}
+
+func buildJSONView(pwData PlaywrightJSON) (string, error) {
+	return renderJSONView(buildNodeTree(pwData))
+}
+
+func renderJSONView(nodes *treeNode) (string, error) {
+	data, err := json.MarshalIndent(jsonTree(nodes), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func jsonTree(nodes *treeNode) JSONTree {
+	totalTests, totalFiles := nodes.totals()
+	out := JSONTree{
+		Version:    jsonSchemaVersion,
+		TotalTests: totalTests,
+		TotalFiles: totalFiles,
+		Children:   []JSONNode{},
+	}
+	for _, child := range nodes.Children {
+		out.Children = append(out.Children, toJSONNode(child))
+	}
+	return out
+}
+
+func toJSONNode(n *treeNode) JSONNode {
+	kind := nodeKindNames[n.Kind]
+	switch n.Kind {
+	case groupNode:
+		kind = n.Group
+	case detailNode:
+		kind = n.Detail
+	}
+	node := JSONNode{
+		Type:     kind,
+		Title:    n.Title,
+		File:     n.File,
+		Line:     n.Line,
+		Tests:    n.testCount(),
+		Status:   n.Status,
+		Duration: n.Duration.Milliseconds(),
+	}
+	if n.Change != nil {
+		node.Change = n.Change.Kind
+		for _, field := range n.Change.Changes {
+			node.Changes = append(node.Changes, field.String())
+		}
+	}
+	if n.Spec != nil {
+		node.JSONSpec = &JSONSpec{
+			Tags:     append([]string{}, sortedKeys(n.Spec.Tags)...),
+			Projects: append([]string{}, sortedKeys(n.Spec.Projects)...),
+			Skipped:  n.Spec.Skipped,
+			Fixme:    n.Spec.Fixme,
+			Fail:     n.Spec.Fail,
+		}
+		for project := range n.Spec.Projects {
+			if outcome, ok := n.Spec.Outcomes[project]; ok {
+				if node.JSONSpec.Results == nil {
+					node.JSONSpec.Results = map[string]JSONResult{}
+				}
+				node.JSONSpec.Results[project] = JSONResult{
+					Status:   outcome.Status,
+					Retries:  outcome.Retries,
+					Duration: outcome.Duration.Milliseconds(),
+				}
+			}
+		}
+	}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, toJSONNode(child))
+	}
+	return node
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildJSONView(t *testing.T) {
+	pwData := PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "cart.spec.ts",
+				File:  "cart.spec.ts",
+				Suites: []Suite{
+					{
+						Title: "Cart",
+						File:  "cart.spec.ts",
+						Line:  3,
+						Specs: []Spec{
+							{
+								Title: "adds item",
+								File:  "cart.spec.ts",
+								Line:  4,
+								Tags:  []string{"smoke"},
+								Tests: []TestInstance{
+									{ProjectName: "webkit", Annotations: []Annotation{{Type: "skip"}}},
+									{ProjectName: "chromium"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out, err := buildJSONView(pwData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var parsed JSONTree
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if parsed.Version != jsonSchemaVersion {
+		t.Errorf("Expected version %d, got %d", jsonSchemaVersion, parsed.Version)
+	}
+	if parsed.TotalTests != 2 || parsed.TotalFiles != 1 {
+		t.Errorf("Expected 2 tests in 1 file, got %d in %d", parsed.TotalTests, parsed.TotalFiles)
+	}
+
+	file := parsed.Children[0]
+	if file.Type != "file" || file.Tests != 2 {
+		t.Fatalf("Unexpected file node %+v", file)
+	}
+	suite := file.Children[0]
+	if suite.Type != "suite" || suite.Title != "Cart" || suite.Line != 3 {
+		t.Fatalf("Unexpected suite node %+v", suite)
+	}
+	spec := suite.Children[0]
+	if spec.Type != "spec" || spec.JSONSpec == nil {
+		t.Fatalf("Unexpected spec node %+v", spec)
+	}
+	if len(spec.Projects) != 2 || spec.Projects[0] != "chromium" {
+		t.Errorf("Expected sorted projects, got %v", spec.Projects)
+	}
+	if len(spec.Tags) != 1 || !spec.Skipped || spec.Fail {
+		t.Errorf("Unexpected spec details %+v", spec.JSONSpec)
+	}
+}
+
+func TestBuildJSONView_Results(t *testing.T) {
+	out, err := buildJSONView(loadResultsFixture(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var parsed JSONTree
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	spec := parsed.Children[0].Children[0].Children[0]
+	expected := JSONResult{Status: "failed", Retries: 1, Duration: 12160}
+	if spec.Results["chromium"] != expected {
+		t.Errorf("Expected %+v for chromium, got %+v", expected, spec.Results["chromium"])
+	}
+	if spec.Results["firefox"].Status != "passed" {
+		t.Errorf("Expected firefox to pass, got %+v", spec.Results["firefox"])
+	}
+}
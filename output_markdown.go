@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+// buildMarkdownView renders the tree as GitHub-flavored markdown, with each
+// file folded into a <details> block so large trees stay readable in PR comments.
+func buildMarkdownView(pwData PlaywrightJSON, display DisplayOptions) string {
+	return renderMarkdownView(buildNodeTree(pwData), display)
+}
+
+func renderMarkdownView(nodes *treeNode, display DisplayOptions) string {
+	var b strings.Builder
+	b.WriteString("**" + treeCounter(nodes) + "**\n")
+	writeMarkdownFiles(&b, nodes, display)
+	return b.String()
+}
+
+// writeMarkdownFiles writes one <details> block per top-level node.
+func writeMarkdownFiles(b *strings.Builder, nodes *treeNode, display DisplayOptions) {
+	for _, top := range nodes.Children {
+		title := top.Title
+		if top.Kind == dirNode {
+			title += "/"
+		}
+		tests := top.testCount()
+		fmt.Fprintf(b, "\n<details>\n<summary><code>%s</code> (%d test%s)</summary>\n\n",
+			html.EscapeString(title), tests, pluralize(tests))
+		for _, child := range top.Children {
+			writeMarkdownNode(b, child, 0, display)
+		}
+		b.WriteString("\n</details>\n")
+	}
+}
+
+func writeMarkdownNode(b *strings.Builder, n *treeNode, depth int, display DisplayOptions) {
+	indent := strings.Repeat("  ", depth)
+
+	switch n.Kind {
+	case dirNode, fileNode:
+		title := n.Title
+		if n.Kind == dirNode {
+			title += "/"
+		}
+		tests := n.testCount()
+		fmt.Fprintf(b, "%s- `%s` (%d test%s)\n", indent, title, tests, pluralize(tests))
+	case suiteNode:
+		label := "**" + markdownEscaper.Replace(n.Title) + "**"
+		if display.ShowFileLines && n.Line > 0 {
+			label += fmt.Sprintf(" `%s:%d`", n.File, n.Line)
+		}
+		b.WriteString(indent + "- " + label + "\n")
+	case specNode:
+		label := markdownSpecLabel(n.Spec, display)
+		if n.Change != nil {
+			label = markdownDiffLabel(n, display)
+		}
+		b.WriteString(indent + "- " + label + "\n")
+	case resultNode:
+		b.WriteString(indent + "- " + markdownEscaper.Replace(n.Title) + "\n")
+	case detailNode:
+		writeMarkdownDetail(b, n, indent)
+	}
+
+	for _, child := range n.Children {
+		writeMarkdownNode(b, child, depth+1, display)
+	}
+}
+
+// writeMarkdownDetail puts multi-line details, such as error messages and code
+// snippets, in a fenced block inside the list item.
+func writeMarkdownDetail(b *strings.Builder, n *treeNode, indent string) {
+	if n.Detail == "attachment" {
+		path := "(inline)"
+		if n.File != "" {
+			path = "`" + n.File + "`"
+		}
+		b.WriteString(indent + "- " + markdownEscaper.Replace(n.Title) + " " + path + "\n")
+		return
+	}
+	if n.Detail == "step" {
+		label := markdownEscaper.Replace(n.Title)
+		if n.Status == "failed" {
+			label = "**" + label + "** ✘"
+		}
+		b.WriteString(indent + "- " + label + " _" + formatDuration(n.Duration) + "_\n")
+		return
+	}
+	if !strings.Contains(n.Title, "\n") && n.Detail != "snippet" {
+		b.WriteString(indent + "- `" + strings.ReplaceAll(n.Title, "`", "'") + "`\n")
+		return
+	}
+	fence := "```"
+	for strings.Contains(n.Title, fence) {
+		fence += "`"
+	}
+	b.WriteString(indent + "- " + fence + "\n")
+	for _, line := range strings.Split(n.Title, "\n") {
+		b.WriteString(strings.TrimRight(indent+"  "+line, " ") + "\n")
+	}
+	b.WriteString(indent + "  " + fence + "\n")
+}
+
+func markdownSpecLabel(as *aggSpec, display DisplayOptions) string {
+	label := markdownEscaper.Replace(as.Title)
+	if as.Skipped {
+		label += " **\\[skipped\\]**"
+	}
+	if as.Fixme {
+		label += " **\\[fixme\\]**"
+	}
+	if as.Fail {
+		label += " **\\[fail\\]**"
+	}
+
+	if display.ShowProjects && len(as.Projects) > 0 {
+		var projects []string
+		for _, project := range sortedKeys(as.Projects) {
+			if outcome, ok := as.Outcomes[project]; ok {
+				project += ": " + outcome.String()
+			}
+			projects = append(projects, project)
+		}
+		label += " _(" + markdownEscaper.Replace(strings.Join(projects, ", ")) + ")_"
+	} else if outcome := worstOutcome(as); outcome != "" {
+		label += " **\\[" + outcome + "\\]**"
+	}
+	if display.ShowTags {
+		for _, tag := range sortedKeys(as.Tags) {
+			label += " `" + tag + "`"
+		}
+	}
+	if display.ShowFileLines {
+		label += fmt.Sprintf(" `%s:%d`", as.File, as.Line)
+	}
+	return label
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMarkdownView(t *testing.T) {
+	pwData := PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "cart.spec.ts",
+				File:  "cart.spec.ts",
+				Suites: []Suite{
+					{
+						Title: "Cart",
+						File:  "cart.spec.ts",
+						Line:  3,
+						Specs: []Spec{
+							{
+								Title: "adds *item*",
+								File:  "cart.spec.ts",
+								Line:  4,
+								Tags:  []string{"@smoke"},
+								Tests: []TestInstance{
+									{ProjectName: "chromium", Annotations: []Annotation{{Type: "fixme"}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("AllDetails", func(t *testing.T) {
+		output := buildMarkdownView(pwData, DisplayOptions{ShowProjects: true, ShowTags: true, ShowFileLines: true})
+
+		for _, expected := range []string{
+			"**Total: 1 test in 1 file**",
+			"<summary><code>cart.spec.ts</code> (1 test)</summary>",
+			"- **Cart** `cart.spec.ts:3`",
+			"  - adds \\*item\\* **\\[fixme\\]** _(chromium)_ `@smoke` `cart.spec.ts:4`",
+			"</details>",
+		} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected output to contain %q\nGot:\n%s", expected, output)
+			}
+		}
+	})
+
+	t.Run("HonorsDisplayOptions", func(t *testing.T) {
+		output := buildMarkdownView(pwData, DisplayOptions{})
+
+		if strings.Contains(output, "chromium") || strings.Contains(output, "@smoke") || strings.Contains(output, "cart.spec.ts:4") {
+			t.Errorf("Expected projects, tags and file lines to be hidden\nGot:\n%s", output)
+		}
+	})
+}
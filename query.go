@@ -0,0 +1,452 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// queryFields are the qualifiers accepted before a ':' in --query terms.
+var queryFields = map[string]bool{
+	"title":      true,
+	"file":       true,
+	"tag":        true,
+	"project":    true,
+	"annotation": true,
+	"line":       true,
+}
+
+// queryAnnotations maps the accepted annotation: values to annotation types.
+var queryAnnotations = map[string]string{
+	"skip":    "skip",
+	"skipped": "skip",
+	"fixme":   "fixme",
+	"fail":    "fail",
+}
+
+// queryTarget is a single test (one spec in one project) as seen by a query.
+type queryTarget struct {
+	Describes   []string
+	Title       string
+	File        string
+	Line        int
+	Tags        []string
+	Project     string
+	Annotations []string
+	// Outcome is passed, failed, flaky or skipped for the tests of run
+	// reports, and empty otherwise.
+	Outcome string
+}
+
+type queryExpr interface {
+	eval(t *queryTarget) bool
+}
+
+type andExpr struct{ left, right queryExpr }
+type orExpr struct{ left, right queryExpr }
+type notExpr struct{ expr queryExpr }
+
+type termExpr struct {
+	field      string
+	line       int
+	annotation string
+	title      textMatcher
+	file       textMatcher
+	tag        textMatcher
+	project    textMatcher
+}
+
+func (e andExpr) eval(t *queryTarget) bool { return e.left.eval(t) && e.right.eval(t) }
+func (e orExpr) eval(t *queryTarget) bool  { return e.left.eval(t) || e.right.eval(t) }
+func (e notExpr) eval(t *queryTarget) bool { return !e.expr.eval(t) }
+
+// newTermExpr compiles a term; an empty field matches titles, files and tags
+// like --filter terms.
+func newTermExpr(field, value string) (termExpr, error) {
+	term := termExpr{field: field}
+	var err error
+
+	switch field {
+	case "title":
+		term.title, err = newTextMatcher(value)
+	case "file":
+		term.file, err = newFileMatcher(value)
+	case "tag":
+		term.tag, err = newTagMatcher(value, true)
+	case "project":
+		term.project, err = newExactMatcher(value)
+	case "annotation":
+		annotation, ok := queryAnnotations[value]
+		if !ok {
+			return term, fmt.Errorf("unknown annotation %q, expected skip, fixme or fail", value)
+		}
+		term.annotation = annotation
+	case "line":
+		term.line, err = strconv.Atoi(value)
+		if err != nil {
+			return term, fmt.Errorf("invalid line number %q", value)
+		}
+	default:
+		if term.title, err = newTextMatcher(value); err != nil {
+			return term, err
+		}
+		if term.file, err = newFileMatcher(value); err != nil {
+			return term, err
+		}
+		term.tag, err = newTagMatcher(value, false)
+	}
+
+	return term, err
+}
+
+func (e termExpr) eval(t *queryTarget) bool {
+	switch e.field {
+	case "title":
+		return e.matchesTitle(t)
+	case "file":
+		return e.file(t.File)
+	case "tag":
+		return e.matchesTag(t)
+	case "project":
+		return e.project(t.Project)
+	case "annotation":
+		for _, ann := range t.Annotations {
+			if ann == e.annotation {
+				return true
+			}
+		}
+		return false
+	case "line":
+		return t.Line == e.line
+	}
+	return e.matchesTitle(t) || e.file(t.File) || e.matchesTag(t)
+}
+
+func (e termExpr) matchesTitle(t *queryTarget) bool {
+	if e.title(t.Title) {
+		return true
+	}
+	for _, d := range t.Describes {
+		if e.title(d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e termExpr) matchesTag(t *queryTarget) bool {
+	for _, tag := range t.Tags {
+		if e.tag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryError reports a problem at a 1-based column of the query string.
+type QueryError struct {
+	Column  int
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm
+)
+
+type queryToken struct {
+	kind   tokenKind
+	field  string
+	value  string
+	column int
+}
+
+func isQueryDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func tokenizeQuery(query string) ([]queryToken, error) {
+	runes := []rune(query)
+	var tokens []queryToken
+	i := 0
+
+	readQuoted := func() (string, error) {
+		start := i
+		i++
+		var b strings.Builder
+		for i < len(runes) {
+			switch runes[i] {
+			case '\\':
+				if i+1 < len(runes) {
+					i++
+					b.WriteRune(runes[i])
+				}
+			case '"':
+				i++
+				return b.String(), nil
+			default:
+				b.WriteRune(runes[i])
+			}
+			i++
+		}
+		return "", &QueryError{Column: start + 1, Message: "unterminated quoted string"}
+	}
+
+	for i < len(runes) {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, column: column})
+			i++
+		case r == '"':
+			value, err := readQuoted()
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenTerm, value: value, column: column})
+		default:
+			start := i
+			for i < len(runes) && !isQueryDelimiter(runes[i]) && runes[i] != ':' {
+				i++
+			}
+			word := string(runes[start:i])
+
+			if i < len(runes) && runes[i] == ':' && queryFields[strings.ToLower(word)] {
+				field := strings.ToLower(word)
+				i++
+				var value string
+				if i < len(runes) && runes[i] == '"' {
+					v, err := readQuoted()
+					if err != nil {
+						return nil, err
+					}
+					value = v
+				} else {
+					valueStart := i
+					for i < len(runes) && !isQueryDelimiter(runes[i]) {
+						i++
+					}
+					value = string(runes[valueStart:i])
+				}
+				if value == "" {
+					return nil, &QueryError{Column: i + 1, Message: fmt.Sprintf("expected a value after %q", field+":")}
+				}
+				tokens = append(tokens, queryToken{kind: tokenTerm, field: field, value: value, column: column})
+				continue
+			}
+
+			// Not a known qualifier: the colon belongs to the value.
+			for i < len(runes) && !isQueryDelimiter(runes[i]) {
+				i++
+			}
+			word = string(runes[start:i])
+
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, queryToken{kind: tokenAnd, column: column})
+			case "or":
+				tokens = append(tokens, queryToken{kind: tokenOr, column: column})
+			case "not":
+				tokens = append(tokens, queryToken{kind: tokenNot, column: column})
+			default:
+				tokens = append(tokens, queryToken{kind: tokenTerm, value: word, column: column})
+			}
+		}
+	}
+
+	tokens = append(tokens, queryToken{kind: tokenEOF, column: len(runes) + 1})
+	return tokens, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseQuery parses expressions such as
+//
+//	tag:@smoke and (file:checkout or title:"Editing") and not project:webkit
+//
+// where "not" binds tighter than "and", which binds tighter than "or".
+func parseQuery(query string) (queryExpr, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, &QueryError{Column: tok.column, Message: "unexpected ')'"}
+		}
+		return nil, &QueryError{Column: tok.column, Message: "expected 'and' or 'or'"}
+	}
+	return expr, nil
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryExpr, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryExpr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &QueryError{Column: closing.column, Message: "expected ')'"}
+		}
+		return expr, nil
+	case tokenTerm:
+		term, err := newTermExpr(tok.field, tok.value)
+		if err != nil {
+			return nil, &QueryError{Column: tok.column, Message: err.Error()}
+		}
+		return term, nil
+	case tokenEOF:
+		return nil, &QueryError{Column: tok.column, Message: "unexpected end of query"}
+	case tokenRParen:
+		return nil, &QueryError{Column: tok.column, Message: "unexpected ')'"}
+	default:
+		return nil, &QueryError{Column: tok.column, Message: "expected a term"}
+	}
+}
+
+// formatQueryError points at the offending column under the query.
+func formatQueryError(query string, err error) string {
+	qe, ok := err.(*QueryError)
+	if !ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s\n  %s\n  %s^", qe.Error(), query, strings.Repeat(" ", qe.Column-1))
+}
+
+// filterSuitesByQuery keeps the tests for which the query holds, evaluating
+// it once per spec and project so project and annotation terms are exact.
+func filterSuitesByQuery(suites []Suite, expr queryExpr) []Suite {
+	return filterSuitesByQueryWithin(suites, expr, nil)
+}
+
+func filterSuitesByQueryWithin(suites []Suite, expr queryExpr, describes []string) []Suite {
+	var filtered []Suite
+
+	for _, suite := range suites {
+		path := describes
+		if suite.Title != "" && suite.Title != suite.File {
+			path = append(append([]string{}, describes...), suite.Title)
+		}
+
+		suite.Suites = filterSuitesByQueryWithin(suite.Suites, expr, path)
+
+		var newSpecs []Spec
+		for _, spec := range suite.Specs {
+			var filteredTests []TestInstance
+			for _, test := range spec.Tests {
+				target := &queryTarget{
+					Describes: path,
+					Title:     spec.Title,
+					File:      spec.File,
+					Line:      spec.Line,
+					Tags:      spec.Tags,
+					Project:   test.ProjectName,
+				}
+				for _, ann := range test.Annotations {
+					target.Annotations = append(target.Annotations, ann.Type)
+				}
+				if outcome := outcomeOf(test); outcome != nil {
+					target.Outcome = outcomeStyleName(outcome.Status)
+				}
+				if expr.eval(target) {
+					filteredTests = append(filteredTests, test)
+				}
+			}
+			if len(filteredTests) > 0 {
+				spec.Tests = filteredTests
+				newSpecs = append(newSpecs, spec)
+			}
+		}
+		suite.Specs = newSpecs
+
+		if len(suite.Suites) > 0 || len(suite.Specs) > 0 {
+			filtered = append(filtered, suite)
+		}
+	}
+
+	return filtered
+}
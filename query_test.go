@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func querySuites() []Suite {
+	return []Suite{
+		{
+			Title: "checkout.spec.ts",
+			File:  "checkout.spec.ts",
+			Suites: []Suite{
+				{
+					Title: "Editing",
+					File:  "checkout.spec.ts",
+					Specs: []Spec{
+						{
+							Title: "edits address",
+							File:  "checkout.spec.ts",
+							Line:  5,
+							Tags:  []string{"@smoke"},
+							Tests: []TestInstance{{ProjectName: "chromium"}, {ProjectName: "webkit"}},
+						},
+						{
+							Title: "edits card",
+							File:  "checkout.spec.ts",
+							Line:  9,
+							Tests: []TestInstance{{ProjectName: "chromium", Annotations: []Annotation{{Type: "skip"}}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Title: "cart.spec.ts",
+			File:  "cart.spec.ts",
+			Specs: []Spec{
+				{
+					Title: "adds item",
+					File:  "cart.spec.ts",
+					Line:  3,
+					Tags:  []string{"@smoke"},
+					Tests: []TestInstance{{ProjectName: "chromium"}},
+				},
+			},
+		},
+	}
+}
+
+func queryResults(t *testing.T, query string) []string {
+	t.Helper()
+	expr, err := parseQuery(query)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing %q: %v", query, err)
+	}
+	var results []string
+	var walk func(suites []Suite)
+	walk = func(suites []Suite) {
+		for _, suite := range suites {
+			for _, spec := range suite.Specs {
+				for _, test := range spec.Tests {
+					results = append(results, spec.Title+"/"+test.ProjectName)
+				}
+			}
+			walk(suite.Suites)
+		}
+	}
+	walk(filterSuitesByQuery(querySuites(), expr))
+	return results
+}
+
+func TestFilterSuitesByQuery(t *testing.T) {
+	cases := map[string][]string{
+		`tag:@smoke and file:checkout`:                                             {"edits address/chromium", "edits address/webkit"},
+		`tag:@smoke and (file:checkout or title:"Editing") and not project:webkit`: {"edits address/chromium"},
+		`title:Editing and annotation:skipped`:                                     {"edits card/chromium"},
+		`line:3 or NOT (smoke)`:                                                    {"edits card/chromium", "adds item/chromium"},
+		`project:firefox`:                                                          nil,
+	}
+	for query, expected := range cases {
+		got := queryResults(t, query)
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("Query %q: expected %v, got %v", query, expected, got)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	cases := map[string]int{
+		`tag:@smoke and (file:checkout`: 30,
+		`tag:@smoke smoke`:              12,
+		`title:"Editing`:                7,
+		`line:abc`:                      1,
+		`annotation:todo`:               1,
+		`tag:@smoke and`:                15,
+		`file:`:                         6,
+		`smoke)`:                        6,
+	}
+	for query, column := range cases {
+		_, err := parseQuery(query)
+		qe, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("Query %q: expected a QueryError, got %v", query, err)
+			continue
+		}
+		if qe.Column != column {
+			t.Errorf("Query %q: expected error at column %d, got %d (%s)", query, column, qe.Column, qe.Message)
+		}
+	}
+}
+
+func TestFormatQueryError(t *testing.T) {
+	query := `tag:@smoke and (file:checkout`
+	_, err := parseQuery(query)
+	expected := "column 30: expected ')'\n  " + query + "\n  " + strings.Repeat(" ", 29) + "^"
+	if got := formatQueryError(query, err); got != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// testOutcome is the result of a test in one project, after retries.
+type testOutcome struct {
+	Status   string
+	Retries  int
+	Duration time.Duration
+}
+
+// outcomeRanks orders outcomes from worst to best, for rolling them up.
+var outcomeRanks = map[string]int{
+	"failed":      0,
+	"timedOut":    1,
+	"interrupted": 2,
+	"flaky":       3,
+	"passed":      4,
+	"skipped":     5,
+}
+
+// outcomeOf maps the reporter's expected/unexpected/flaky/skipped status to
+// the outcome shown in the tree. Tests from --list reports have no results
+// and therefore no outcome.
+func outcomeOf(test TestInstance) *testOutcome {
+	if len(test.Results) == 0 {
+		return nil
+	}
+
+	outcome := &testOutcome{Retries: len(test.Results) - 1}
+	for _, result := range test.Results {
+		outcome.Duration += time.Duration(result.Duration) * time.Millisecond
+	}
+
+	last := test.Results[len(test.Results)-1]
+	switch test.Status {
+	case "expected":
+		outcome.Status = "passed"
+		if last.Status == "skipped" {
+			outcome.Status = "skipped"
+		}
+	case "flaky":
+		outcome.Status = "flaky"
+	case "skipped":
+		outcome.Status = "skipped"
+	default:
+		outcome.Status = "failed"
+		if last.Status == "timedOut" || last.Status == "interrupted" {
+			outcome.Status = last.Status
+		}
+	}
+	return outcome
+}
+
+// worstOutcome rolls up the outcomes of a spec's projects, or returns "" when
+// the spec has not been run.
+func worstOutcome(as *aggSpec) string {
+	worst := ""
+	for project := range as.Projects {
+		if outcome, ok := as.Outcomes[project]; ok {
+			if worst == "" || outcomeRanks[outcome.Status] < outcomeRanks[worst] {
+				worst = outcome.Status
+			}
+		}
+	}
+	return worst
+}
+
+// nodeOutcome is the worst outcome of the specs beneath a node.
+func nodeOutcome(n *treeNode) string {
+	if n.Kind == specNode {
+		return worstOutcome(n.Spec)
+	}
+	worst := ""
+	for _, child := range n.Children {
+		if status := nodeOutcome(child); status != "" && (worst == "" || outcomeRanks[status] < outcomeRanks[worst]) {
+			worst = status
+		}
+	}
+	return worst
+}
+
+// outcomeStyleName maps an outcome to its style entry; timeouts and
+// interruptions are shown as failures.
+func outcomeStyleName(status string) string {
+	switch status {
+	case "timedOut", "interrupted":
+		return "failed"
+	}
+	return status
+}
+
+func (o *testOutcome) String() string {
+	if o.Status == "skipped" {
+		return o.Status
+	}
+	s := fmt.Sprintf("%s %s", o.Status, formatDuration(o.Duration))
+	if o.Retries == 1 {
+		s += " after 1 retry"
+	} else if o.Retries > 1 {
+		s += fmt.Sprintf(" after %d retries", o.Retries)
+	}
+	return s
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// outcomeOrder is the order of outcomes in counters.
+var outcomeOrder = []string{"passed", "failed", "timedOut", "interrupted", "flaky", "skipped"}
+
+// outcomeSummary formats the outcome counts, e.g. "70 passed, 3 failed", or
+// returns "" for trees from --list reports.
+func outcomeSummary(n *treeNode) string {
+	counts := outcomeCounts(n)
+	var parts []string
+	for _, status := range outcomeOrder {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// outcomeCounts tallies the outcomes of the distinct tests beneath a node.
+func outcomeCounts(n *treeNode) map[string]int {
+	counts := map[string]int{}
+	seen := map[string]bool{}
+
+	var walk func(n *treeNode, scope string)
+	walk = func(n *treeNode, scope string) {
+		if n.Kind == configNode {
+			scope = n.Title
+		}
+		if n.Kind == specNode {
+			for project := range n.Spec.Projects {
+				key := fmt.Sprintf("%s|%s:%d:%s|%s", scope, n.Spec.File, n.Spec.Line, n.Spec.Title, project)
+				if outcome, ok := n.Spec.Outcomes[project]; ok && !seen[key] {
+					seen[key] = true
+					counts[outcome.Status]++
+				}
+			}
+		}
+		for _, child := range n.Children {
+			walk(child, scope)
+		}
+	}
+	walk(n, "")
+
+	return counts
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func loadResultsFixture(t *testing.T) PlaywrightJSON {
+	t.Helper()
+	raw, err := os.ReadFile("test-data/sample-results.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pwData, err := loadPlaywrightJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pwData
+}
+
+func TestOutcomeOf(t *testing.T) {
+	cases := []struct {
+		test     TestInstance
+		expected string
+		retries  int
+	}{
+		{TestInstance{Status: "expected", Results: []TestResult{{Status: "passed", Duration: 1200}}}, "passed 1.2s", 0},
+		{TestInstance{Status: "unexpected", Results: []TestResult{{Status: "failed", Duration: 300}, {Status: "failed", Duration: 400, Retry: 1}}}, "failed 700ms after 1 retry", 1},
+		{TestInstance{Status: "unexpected", Results: []TestResult{{Status: "timedOut", Duration: 30000}}}, "timedOut 30.0s", 0},
+		{TestInstance{Status: "flaky", Results: []TestResult{{Status: "failed"}, {Status: "failed"}, {Status: "passed"}}}, "flaky 0ms after 2 retries", 2},
+		{TestInstance{Status: "skipped", Results: []TestResult{{Status: "skipped"}}}, "skipped", 0},
+		{TestInstance{Status: "expected", ExpectedStatus: "failed", Results: []TestResult{{Status: "failed", Duration: 90 * 1000}}}, "passed 1m30s", 0},
+	}
+	for _, c := range cases {
+		outcome := outcomeOf(c.test)
+		if outcome.String() != c.expected || outcome.Retries != c.retries {
+			t.Errorf("Expected %q with %d retries, got %q with %d", c.expected, c.retries, outcome, outcome.Retries)
+		}
+	}
+
+	if outcome := outcomeOf(TestInstance{Status: "skipped"}); outcome != nil {
+		t.Errorf("Expected no outcome for a listed test, got %v", outcome)
+	}
+}
+
+func TestBuildTreeView_Results(t *testing.T) {
+	pwData := loadResultsFixture(t)
+	root := buildNodeTree(pwData)
+
+	checkout := root.Children[0]
+	if nodeOutcome(checkout) != "failed" || nodeOutcome(root.Children[1]) != "flaky" {
+		t.Errorf("Expected checkout to fail and login to be flaky, got %s and %s", nodeOutcome(checkout), nodeOutcome(root.Children[1]))
+	}
+	if d := checkout.Children[0].Children[0].Spec.Outcomes["chromium"].Duration; d != 12160*time.Millisecond {
+		t.Errorf("Expected the durations of all attempts to add up, got %v", d)
+	}
+
+	output := renderTreeView(root, map[string]lipgloss.Style{}, DisplayOptions{ShowProjects: true}, DisplayEmojis{})
+	for _, want := range []string{
+		"shows the cart total (chromium: failed 12.2s after 1 retry, firefox: passed 2.3s)",
+		"logs in (chromium: flaky 5.3s after 1 retry, firefox: passed 950ms)",
+		"Total: 10 tests in 2 files (5 passed, 1 failed, 1 timedOut, 1 flaky, 2 skipped)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	output = renderTreeView(root, map[string]lipgloss.Style{}, DisplayOptions{}, DisplayEmojis{})
+	if !strings.Contains(output, "applies a coupon [timedOut]") {
+		t.Errorf("Expected the outcome as a badge without projects, got:\n%s", output)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// nodeTarget returns the Playwright location filter that selects a node,
+// matching the (file:line) strings printed in the tree.
+func nodeTarget(n *treeNode) string {
+	switch n.Kind {
+	case dirNode, fileNode:
+		return n.File
+	case suiteNode, specNode:
+		return fmt.Sprintf("%s:%d", n.File, n.Line)
+	}
+	return ""
+}
+
+// runGroup is one Playwright invocation: location filters sharing the same
+// projects.
+type runGroup struct {
+	Targets  []string
+	Projects []string
+}
+
+// runGroups collects the deduplicated location filters for the given nodes
+// and groups them by the projects their specs run in, so a chromium-only
+// spec is not also run in webkit because a webkit-only one was selected.
+func runGroups(nodes []*treeNode) []runGroup {
+	seen := map[string]bool{}
+	byProjects := map[string]*runGroup{}
+	var groups []*runGroup
+
+	var collectProjects func(n *treeNode, projectSet map[string]bool)
+	collectProjects = func(n *treeNode, projectSet map[string]bool) {
+		if n.Kind == specNode {
+			for p := range n.Spec.Projects {
+				projectSet[p] = true
+			}
+		}
+		for _, child := range n.Children {
+			collectProjects(child, projectSet)
+		}
+	}
+
+	// Group nodes have no location of their own, so their children are
+	// targeted instead.
+	var collectTargets func(n *treeNode)
+	collectTargets = func(n *treeNode) {
+		target := nodeTarget(n)
+		if target == "" {
+			for _, child := range n.Children {
+				collectTargets(child)
+			}
+			return
+		}
+		if seen[target] {
+			return
+		}
+		seen[target] = true
+
+		projectSet := map[string]bool{}
+		collectProjects(n, projectSet)
+		projects := sortedKeys(projectSet)
+		key := strings.Join(projects, "\x00")
+		group, ok := byProjects[key]
+		if !ok {
+			group = &runGroup{Projects: projects}
+			byProjects[key] = group
+			groups = append(groups, group)
+		}
+		group.Targets = append(group.Targets, target)
+	}
+
+	for _, n := range nodes {
+		collectTargets(n)
+	}
+
+	var out []runGroup
+	for _, group := range groups {
+		out = append(out, *group)
+	}
+	return out
+}
+
+// findNodes returns the file node for a "file" target, or the suites and
+// specs declared at a "file:line" target.
+func findNodes(root *treeNode, target string) []*treeNode {
+	target = strings.Trim(strings.TrimSpace(target), "()")
+	file, line := target, 0
+	if i := strings.LastIndex(target, ":"); i != -1 {
+		if n, err := strconv.Atoi(target[i+1:]); err == nil {
+			file, line = target[:i], n
+		}
+	}
+
+	var matches []*treeNode
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.File == file {
+			if line == 0 && n.Kind == fileNode {
+				matches = append(matches, n)
+			}
+			if line != 0 && n.Line == line && (n.Kind == suiteNode || n.Kind == specNode) {
+				matches = append(matches, n)
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return matches
+}
+
+func resolveTargets(root *treeNode, args []string) ([]*treeNode, error) {
+	var nodes []*treeNode
+	for _, arg := range args {
+		matches := findNodes(root, arg)
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no tests found at %s", arg)
+		}
+		nodes = append(nodes, matches...)
+	}
+	return nodes, nil
+}
+
+// runNodes runs Playwright once per group of targets, and exits with the
+// first failing exit code once all groups ran.
+func runNodes(nodes []*treeNode) {
+	exitCode := 0
+	for _, group := range runGroups(nodes) {
+		err := runPlaywrightTests(group.Targets, group.Projects, configFile)
+		if err == nil {
+			continue
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			fmt.Println("Error running Playwright:", err)
+			os.Exit(1)
+		}
+		if exitCode == 0 {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveTargets(t *testing.T) {
+	pwData := PlaywrightJSON{
+		Suites: []Suite{
+			{
+				Title: "cart.spec.ts",
+				File:  "cart.spec.ts",
+				Suites: []Suite{
+					{
+						Title: "Cart",
+						File:  "cart.spec.ts",
+						Line:  3,
+						Specs: []Spec{
+							{Title: "adds item", File: "cart.spec.ts", Line: 4, Tests: []TestInstance{{ProjectName: "chromium"}}},
+							{Title: "removes item", File: "cart.spec.ts", Line: 9, Tests: []TestInstance{{ProjectName: "webkit"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+	root := buildNodeTree(pwData)
+
+	t.Run("SpecLocationFromTreeOutput", func(t *testing.T) {
+		nodes, err := resolveTargets(root, []string{"(cart.spec.ts:9)"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		groups := runGroups(nodes)
+		if !reflect.DeepEqual(groups, []runGroup{{Targets: []string{"cart.spec.ts:9"}, Projects: []string{"webkit"}}}) {
+			t.Errorf("Unexpected groups %+v", groups)
+		}
+	})
+
+	t.Run("SuiteUnionsProjects", func(t *testing.T) {
+		nodes, err := resolveTargets(root, []string{"cart.spec.ts:3", "cart.spec.ts:3"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		groups := runGroups(nodes)
+		if !reflect.DeepEqual(groups, []runGroup{{Targets: []string{"cart.spec.ts:3"}, Projects: []string{"chromium", "webkit"}}}) {
+			t.Errorf("Expected a deduplicated suite target, got %+v", groups)
+		}
+	})
+
+	t.Run("SpecsGroupedByProjects", func(t *testing.T) {
+		nodes, err := resolveTargets(root, []string{"cart.spec.ts:4", "cart.spec.ts:9"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []runGroup{
+			{Targets: []string{"cart.spec.ts:4"}, Projects: []string{"chromium"}},
+			{Targets: []string{"cart.spec.ts:9"}, Projects: []string{"webkit"}},
+		}
+		if groups := runGroups(nodes); !reflect.DeepEqual(groups, expected) {
+			t.Errorf("Expected one run per project set, got %+v", groups)
+		}
+	})
+
+	t.Run("WholeFile", func(t *testing.T) {
+		nodes, err := resolveTargets(root, []string{"cart.spec.ts"})
+		if err != nil || len(nodes) != 1 || nodes[0].Kind != fileNode {
+			t.Fatalf("Expected the file node, got %v (err %v)", nodes, err)
+		}
+	})
+
+	t.Run("UnknownLocation", func(t *testing.T) {
+		if _, err := resolveTargets(root, []string{"cart.spec.ts:42"}); err == nil {
+			t.Error("Expected an error for a location without tests")
+		}
+	})
+}
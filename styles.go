@@ -19,22 +19,32 @@ type StyleEntry struct {
 
 type EmojiConfig struct {
 	Root  *string `json:"root,omitempty"`
+	Dir   *string `json:"dir,omitempty"`
 	File  *string `json:"file,omitempty"`
 	Suite *string `json:"suite,omitempty"`
 }
 
 type DisplayEmojis struct {
 	Root  string
+	Dir   string
 	File  string
 	Suite string
 }
 
 type FullConfig struct {
-	Styles         []StyleEntry `json:"styles"`
-	ShowProjects   *bool        `json:"showProjects,omitempty"`
-	ShowTags       *bool        `json:"showTags,omitempty"`
-	ShowFileLines  *bool        `json:"showFileLines,omitempty"`
-	EmojiOverrides EmojiConfig  `json:"emojis,omitempty"`
+	Styles         []StyleEntry  `json:"styles"`
+	ShowProjects   *bool         `json:"showProjects,omitempty"`
+	ShowTags       *bool         `json:"showTags,omitempty"`
+	ShowFileLines  *bool         `json:"showFileLines,omitempty"`
+	EmojiOverrides EmojiConfig   `json:"emojis,omitempty"`
+	Heatmap        HeatmapConfig `json:"heatmap,omitempty"`
+	Lint           LintConfig    `json:"lint,omitempty"`
+}
+
+type HeatmapConfig struct {
+	// Gradient lists the colors from the fastest to the slowest nodes, as
+	// hex or ANSI colors.
+	Gradient []string `json:"gradient,omitempty"`
 }
 
 type DisplayOptions struct {
@@ -56,24 +66,40 @@ func defaultStyles() map[string]lipgloss.Style {
 		"fail":       lipgloss.NewStyle().Foreground(lipgloss.Color("")),
 		"test":       lipgloss.NewStyle().Foreground(lipgloss.Color("")),
 		"counter":    lipgloss.NewStyle(),
+		"dir":        lipgloss.NewStyle().Foreground(lipgloss.Color("")),
 		"file":       lipgloss.NewStyle().Foreground(lipgloss.Color("")),
 		"suite":      lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"passed":     lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"failed":     lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"flaky":      lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"added":      lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"removed":    lipgloss.NewStyle().Foreground(lipgloss.Color("")),
+		"changed":    lipgloss.NewStyle().Foreground(lipgloss.Color("")),
 	}
 }
 
-func loadStyleConfig() (map[string]lipgloss.Style, DisplayOptions, DisplayEmojis) {
+// readConfig parses the first config file found, in the project or in the
+// user's config directory. found is false when there is none.
+func readConfig() (cfg FullConfig, found bool, err error) {
 	paths := []string{
 		"./.pwtree.json",
 		filepath.Join(os.Getenv("HOME"), ".config", "pwtree", "config.json"),
 	}
 
-	var configData []byte
 	for _, path := range paths {
 		if data, err := os.ReadFile(path); err == nil {
-			configData = data
-			break
+			// An empty config file means the defaults.
+			if len(data) == 0 {
+				return cfg, false, nil
+			}
+			return cfg, true, json.Unmarshal(data, &cfg)
 		}
 	}
+	return cfg, false, nil
+}
+
+func loadStyleConfig() (map[string]lipgloss.Style, DisplayOptions, DisplayEmojis) {
+	cfg, found, err := readConfig()
 
 	defaultDisplay := DisplayOptions{
 		ShowProjects:  true,
@@ -82,21 +108,24 @@ func loadStyleConfig() (map[string]lipgloss.Style, DisplayOptions, DisplayEmojis
 	}
 	defaultEmojis := DisplayEmojis{
 		Root:  "",
+		Dir:   "",
 		File:  "",
 		Suite: "",
 	}
 
+	if err == nil && len(cfg.Heatmap.Gradient) > 0 {
+		heatmapGradient = cfg.Heatmap.Gradient
+	}
+
 	if *ciMode {
 		// Return empty styles and emojis in CI mode
 		return map[string]lipgloss.Style{}, defaultDisplay, DisplayEmojis{}
 	}
 
-	if len(configData) == 0 {
+	if !found {
 		return defaultStyles(), defaultDisplay, defaultEmojis
 	}
-
-	var cfg FullConfig
-	if err := json.Unmarshal(configData, &cfg); err != nil {
+	if err != nil {
 		fmt.Printf("Error parsing config: %v\n", err)
 		return defaultStyles(), defaultDisplay, defaultEmojis
 	}
@@ -121,6 +150,7 @@ func loadStyleConfig() (map[string]lipgloss.Style, DisplayOptions, DisplayEmojis
 
 	emojis := DisplayEmojis{
 		Root:  defaultEmojis.Root,
+		Dir:   defaultEmojis.Dir,
 		File:  defaultEmojis.File,
 		Suite: defaultEmojis.Suite,
 	}
@@ -128,6 +158,9 @@ func loadStyleConfig() (map[string]lipgloss.Style, DisplayOptions, DisplayEmojis
 	if cfg.EmojiOverrides.Root != nil {
 		emojis.Root = *cfg.EmojiOverrides.Root
 	}
+	if cfg.EmojiOverrides.Dir != nil {
+		emojis.Dir = *cfg.EmojiOverrides.Dir
+	}
 	if cfg.EmojiOverrides.File != nil {
 		emojis.File = *cfg.EmojiOverrides.File
 	}
@@ -88,3 +88,20 @@ func TestLoadStyleConfig_WithTempFile(t *testing.T) {
 		t.Error("Expected 'enumerator' to be bold")
 	}
 }
+
+func TestReadConfig_EmptyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, ".pwtree.json"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write temp config: %v", err)
+	}
+	originalWD, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change working dir: %v", err)
+	}
+	defer os.Chdir(originalWD)
+	t.Setenv("HOME", tmpDir)
+
+	if _, found, err := readConfig(); found || err != nil {
+		t.Errorf("Expected an empty config to mean the defaults, got found=%v, err=%v", found, err)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type traceChoice struct {
+	Label string
+	Path  string
+}
+
+// traceChoices collects the trace attachments of every attempt of the specs
+// beneath the given nodes.
+func traceChoices(nodes []*treeNode, rootDir string) []traceChoice {
+	var choices []traceChoice
+	seen := map[*aggSpec]bool{}
+
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		if n.Kind == specNode && !seen[n.Spec] {
+			seen[n.Spec] = true
+			for _, project := range sortedKeys(n.Spec.Projects) {
+				for _, result := range n.Spec.Results[project] {
+					for _, a := range result.Attachments {
+						if a.Name != "trace" || a.Path == "" {
+							continue
+						}
+						label := fmt.Sprintf("%s (%s:%d) %s", n.Spec.Title, n.Spec.File, n.Spec.Line, project)
+						if result.Retry > 0 {
+							label += fmt.Sprintf(" retry %d", result.Retry)
+						}
+						choices = append(choices, traceChoice{Label: label + ": " + result.Status, Path: attachmentPath(a, rootDir)})
+					}
+				}
+			}
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	return choices
+}
+
+// chooseTrace asks which trace to open when a node has several.
+func chooseTrace(choices []traceChoice) (traceChoice, error) {
+	if len(choices) == 1 {
+		return choices[0], nil
+	}
+	for i, choice := range choices {
+		fmt.Printf("%d) %s\n", i+1, choice.Label)
+	}
+	fmt.Printf("Open which trace [1-%d]? ", len(choices))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return traceChoice{}, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(choices) {
+		return traceChoice{}, fmt.Errorf("invalid choice %q", strings.TrimSpace(line))
+	}
+	return choices[n-1], nil
+}
+
+func runShowTrace(path string) error {
+	args := []string{"playwright", "show-trace", path}
+	cmd := exec.Command("npx", args...)
+	fmt.Println("Running command:", "npx", strings.Join(args, " "))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func openTraces(nodes []*treeNode, target, rootDir string) {
+	choices := traceChoices(nodes, rootDir)
+	if len(choices) == 0 {
+		fmt.Printf("No traces found for %s\n", target)
+		os.Exit(1)
+	}
+
+	choice, err := chooseTrace(choices)
+	if err != nil {
+		fmt.Printf("Error choosing trace: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(choice.Path); err != nil {
+		fmt.Printf("Error opening trace: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := runShowTrace(choice.Path); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Println("Error running Playwright:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTraceChoices(t *testing.T) {
+	report := loadResultsFixture(t)
+	root := buildNodeTree(report)
+
+	nodes, err := resolveTargets(root, []string{"checkout.spec.ts"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var labels []string
+	for _, choice := range traceChoices(nodes, report.Config.RootDir) {
+		labels = append(labels, choice.Label)
+	}
+	expected := []string{
+		"shows the cart total (checkout.spec.ts:12) chromium: failed",
+		"shows the cart total (checkout.spec.ts:12) chromium retry 1: failed",
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected %v, got %v", expected, labels)
+	}
+
+	nodes, _ = resolveTargets(root, []string{"login.spec.ts:3"})
+	choices := traceChoices(nodes, report.Config.RootDir)
+	if len(choices) != 1 || choices[0].Path != "/work/shop/test-results/login-logs-in-chromium/trace.zip" {
+		t.Errorf("Expected the trace of the failed attempt, got %+v", choices)
+	}
+	if choice, err := chooseTrace(choices); err != nil || choice != choices[0] {
+		t.Errorf("Expected a single trace to be chosen without asking, got %+v, %v", choice, err)
+	}
+}
+
+func TestAttachmentPath(t *testing.T) {
+	cwd, _ := os.Getwd()
+	cases := []struct {
+		path, rootDir, expected string
+	}{
+		{"", "/work/shop", ""},
+		{"/work/shop/trace.zip", "/work/shop", "/work/shop/trace.zip"},
+		{"test-results/trace.zip", "/work/shop", "/work/shop/test-results/trace.zip"},
+		{filepath.Join(cwd, "test-results", "trace.zip"), "/work/shop", filepath.Join("test-results", "trace.zip")},
+	}
+	for _, c := range cases {
+		if got := attachmentPath(Attachment{Path: c.path}, c.rootDir); got != c.expected {
+			t.Errorf("attachmentPath(%q, %q) = %q, expected %q", c.path, c.rootDir, got, c.expected)
+		}
+	}
+}
+
+func TestChooseTrace(t *testing.T) {
+	choices := []traceChoice{{Label: "a", Path: "a.zip"}, {Label: "b", Path: "b.zip"}}
+
+	r, w, _ := os.Pipe()
+	w.WriteString("2\n")
+	w.Close()
+	stdin, stdout := os.Stdin, os.Stdout
+	os.Stdin = r
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdin, os.Stdout = stdin, stdout }()
+
+	choice, err := chooseTrace(choices)
+	if err != nil || choice.Path != "b.zip" {
+		t.Errorf("Expected b.zip, got %+v, %v", choice, err)
+	}
+}
@@ -11,19 +11,70 @@ import (
 )
 
 type PlaywrightJSON struct {
-	Suites []Suite `json:"suites"`
+	Config ReportConfig `json:"config"`
+	Suites []Suite      `json:"suites"`
+}
+
+// ReportConfig is the part of the reporter's config that pwtree uses.
+type ReportConfig struct {
+	RootDir string `json:"rootDir"`
 }
 
 type Annotation struct {
-	Type string `json:"type"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
 }
 
 type TestInstance struct {
-	ProjectName string       `json:"projectName"`
-	Annotations []Annotation `json:"annotations"`
+	ProjectName    string       `json:"projectName"`
+	Annotations    []Annotation `json:"annotations"`
+	Status         string       `json:"status"`
+	ExpectedStatus string       `json:"expectedStatus"`
+	Results        []TestResult `json:"results"`
+}
+
+// TestResult is one attempt of a test; only present in reports of actual runs.
+type TestResult struct {
+	Status      string       `json:"status"`
+	Duration    int          `json:"duration"`
+	Retry       int          `json:"retry"`
+	Errors      []TestError  `json:"errors"`
+	Steps       []TestStep   `json:"steps"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Attachment is a file (path) or inline content (body) saved with a result,
+// such as a trace, screenshot or video.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Path        string `json:"path"`
+	Body        string `json:"body"`
+}
+
+// TestStep is a test.step, hook, fixture or action within a result.
+type TestStep struct {
+	Title    string     `json:"title"`
+	Duration int        `json:"duration"`
+	Error    *TestError `json:"error"`
+	Steps    []TestStep `json:"steps"`
+}
+
+type TestError struct {
+	Message  string         `json:"message"`
+	Stack    string         `json:"stack"`
+	Location *ErrorLocation `json:"location"`
+	Snippet  string         `json:"snippet"`
+}
+
+type ErrorLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
 }
 
 type Spec struct {
+	ID    string         `json:"id"`
 	Title string         `json:"title"`
 	Tags  []string       `json:"tags"`
 	Tests []TestInstance `json:"tests"`
@@ -73,200 +124,214 @@ func buildTreeView(jsonData []byte, styles map[string]lipgloss.Style, display Di
 		return fmt.Sprintf("Error parsing JSON: %v", err)
 	}
 
-	// Styles
-	enumeratorStyle := styles["enumerator"]
-	rootStyle := styles["root"]
-	tagStyle := styles["tag"]
-	projectStyle := styles["project"]
-	fileLineStyle := styles["fileLine"]
-	skippedStyle := styles["skipped"]
-	fixmeStyle := styles["fixme"]
-	failStyle := styles["fail"]
-	testStyle := styles["test"]
-	counterStyle := styles["counter"]
-	fileNodeStyle := styles["file"]
-	suiteNodeStyle := styles["suite"]
+	return renderTreeView(buildNodeTree(pwData), styles, display, emojis)
+}
+
+func renderTreeView(nodes *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) string {
+	return renderTree(nodes, styles, display, emojis, treeCounter(nodes))
+}
 
+// renderTree renders the nodes beneath the root title, followed by the
+// counter line(s).
+func renderTree(nodes *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis, counter string) string {
 	title := strings.TrimSpace(emojis.Root + " Playwright-tree")
 	root := tree.Root(title).
 		Enumerator(tree.RoundedEnumerator).
-		EnumeratorStyle(enumeratorStyle).
-		RootStyle(rootStyle)
+		EnumeratorStyle(styles["enumerator"]).
+		RootStyle(styles["root"])
 
-	seenTests := map[string]bool{}
-	totalTests := 0
-	totalFiles := 0
+	for _, child := range nodes.Children {
+		root.Child(renderNode(child, styles, display, emojis))
+	}
 
-	var processSuite func(s Suite, parent *tree.Tree, parentFile string) (*tree.Tree, bool)
+	return "\n" + root.String() + "\n\n" + styles["counter"].Render(counter) + "\n"
+}
 
-	processSuite = func(suite Suite, parent *tree.Tree, parentFile string) (*tree.Tree, bool) {
-		currentFile := suite.File
-		if currentFile == "" {
-			currentFile = parentFile
-		}
+func treeCounter(n *treeNode) string {
+	totalTests, totalFiles := n.totals()
+	counter := fmt.Sprintf("Total: %d test%s in %d file%s",
+		totalTests, pluralize(totalTests), totalFiles, pluralize(totalFiles))
+	if summary := outcomeSummary(n); summary != "" {
+		counter += " (" + summary + ")"
+	}
+	return counter
+}
 
-		var suiteNode *tree.Tree = parent
-		if suite.Title != "" && suite.Title != suite.File {
-			line := suiteLine(suite)
-			fileLineStr := ""
-			if display.ShowFileLines {
-				fileLineStr = fileLineStyle.Render(fmt.Sprintf("(%s:%d)", currentFile, line))
-			}
-			label := strings.TrimSpace(fmt.Sprintf("%s %s %s", emojis.Suite, suite.Title, fileLineStr))
-			suiteLabel := suiteNodeStyle.Render(label)
-			suiteNode = tree.Root(suiteLabel)
-		}
+func renderNode(n *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) *tree.Tree {
+	node := tree.Root(nodeLabel(n, styles, display, emojis))
+	for _, child := range n.Children {
+		node.Child(renderNode(child, styles, display, emojis))
+	}
+	return node
+}
+
+func nodeLabel(n *treeNode, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) string {
+	if n.Heat != "" {
+		plain := *n
+		plain.Heat = ""
+		label := strings.TrimRight(nodeLabel(&plain, heatStyles(styles, n.Heat), display, emojis), " ")
+		return label + styles["counter"].Render(" "+formatDuration(n.Duration))
+	}
 
-		type aggSpec struct {
-			Title    string
-			File     string
-			Line     int
-			Tags     map[string]bool
-			Projects map[string]bool
-			Skipped  bool
-			Fixme    bool
-			Fail     bool
+	switch n.Kind {
+	case configNode:
+		tests, files := n.totals()
+		return styles["root"].Render(n.Title) +
+			styles["counter"].Render(fmt.Sprintf(" (%d test%s in %d file%s)", tests, pluralize(tests), files, pluralize(files)))
+	case groupNode:
+		tests := n.testCount()
+		return groupStyle(n, styles).Render(n.Title) +
+			styles["counter"].Render(fmt.Sprintf(" (%d test%s)", tests, pluralize(tests)))
+	case dirNode:
+		style, ok := styles["dir"]
+		if !ok {
+			style = styles["file"]
 		}
-		aggSpecs := map[string]*aggSpec{}
-
-		for _, spec := range suite.Specs {
-			key := fmt.Sprintf("%s:%d:%s", spec.File, spec.Line, spec.Title)
-			as, exists := aggSpecs[key]
-			if !exists {
-				as = &aggSpec{
-					Title:    spec.Title,
-					File:     spec.File,
-					Line:     spec.Line,
-					Tags:     map[string]bool{},
-					Projects: map[string]bool{},
-				}
-				aggSpecs[key] = as
-			}
-			for _, tag := range spec.Tags {
-				as.Tags[tag] = true
-			}
-			for _, test := range spec.Tests {
-				as.Projects[test.ProjectName] = true
-				for _, ann := range test.Annotations {
-					switch ann.Type {
-					case "skip":
-						as.Skipped = true
-					case "fixme":
-						as.Fixme = true
-					case "fail":
-						as.Fail = true
-					}
-				}
-			}
+		style = failedStyle(n, style, styles)
+		label := style.Render(strings.TrimSpace(emojis.Dir + " " + n.Title + "/"))
+		tests := n.testCount()
+		return label + styles["counter"].Render(fmt.Sprintf(" (%d test%s)", tests, pluralize(tests)))
+	case fileNode:
+		label := strings.TrimSpace(emojis.File + " " + n.Title)
+		return failedStyle(n, styles["file"], styles).Render(label)
+	case suiteNode:
+		fileLineStr := ""
+		if display.ShowFileLines && n.Line > 0 {
+			fileLineStr = styles["fileLine"].Render(fmt.Sprintf("(%s:%d)", n.File, n.Line))
 		}
-
-		var hasVisibleSpecs bool
-
-		for _, as := range aggSpecs {
-			showAny := *showSkipped || *showFixme || *showFail
-			matchesAnnotation := (!showAny) ||
-				(*showSkipped && as.Skipped) ||
-				(*showFixme && as.Fixme) ||
-				(*showFail && as.Fail)
-
-			if !matchesAnnotation {
-				continue
-			}
-
-			key := fmt.Sprintf("%s:%d:%s", as.File, as.Line, as.Title)
-			if seenTests[key] {
-				continue
-			}
-			seenTests[key] = true
-			hasVisibleSpecs = true
-
-			projectCount := len(as.Projects)
-			totalTests += projectCount
-
-			var tags []string
-			for t := range as.Tags {
-				tags = append(tags, t)
-			}
-			sort.Strings(tags)
-			tagStr := ""
-			if display.ShowTags && len(tags) > 0 {
-				tagStr = tagStyle.Render(" [" + strings.Join(tags, ", ") + "]")
-			}
-
-			var projects []string
-			for p := range as.Projects {
-				projects = append(projects, p)
-			}
-			sort.Strings(projects)
-			projectStr := ""
-			if display.ShowProjects && len(projects) > 0 {
-				projectStr = projectStyle.Render(" (" + strings.Join(projects, ", ") + ")")
-			}
-
-			titleLabel := as.Title
-			if as.Skipped {
-				titleLabel += " [skipped]"
-			}
-			if as.Fixme {
-				titleLabel += " [fixme]"
-			}
-			if as.Fail {
-				titleLabel += " [fail]"
-			}
-
-			var title string
-			switch {
-			case as.Skipped:
-				title = skippedStyle.Render(titleLabel)
-			case as.Fixme:
-				title = fixmeStyle.Render(titleLabel)
-			case as.Fail:
-				title = failStyle.Render(titleLabel)
-			default:
-				title = testStyle.Render(titleLabel)
-			}
-
-			fileLineStr := ""
-			if display.ShowFileLines {
-				fileLineStr = fileLineStyle.Render(fmt.Sprintf("(%s:%d)", as.File, as.Line))
-			}
-			specLabel := fmt.Sprintf("%s%s%s %s", title, projectStr, tagStr, fileLineStr)
-			specNode := tree.Root(specLabel)
-			suiteNode.Child(specNode)
+		label := strings.TrimSpace(fmt.Sprintf("%s %s %s", emojis.Suite, n.Title, fileLineStr))
+		return failedStyle(n, styles["suite"], styles).Render(label)
+	case specNode:
+		if n.Change != nil {
+			return diffLabel(n, styles, display)
 		}
+		return specLabel(n.Spec, styles, display)
+	case resultNode:
+		return styles[outcomeStyleName(n.Status)].Render(n.Title)
+	case detailNode:
+		return detailLabel(n, styles)
+	}
+	return n.Title
+}
 
-		var hasVisibleChildren bool
-		for _, child := range suite.Suites {
-			if childNode, ok := processSuite(child, suiteNode, currentFile); ok {
-				suiteNode.Child(childNode)
-				hasVisibleChildren = true
-			}
+// failedStyle highlights directories, files and suites containing failures.
+func failedStyle(n *treeNode, style lipgloss.Style, styles map[string]lipgloss.Style) lipgloss.Style {
+	if outcomeStyleName(nodeOutcome(n)) == "failed" {
+		if failed, ok := styles["failed"]; ok {
+			return failed
 		}
+	}
+	return style
+}
 
-		if hasVisibleSpecs || hasVisibleChildren {
-			if suiteNode != parent {
-				return suiteNode, true
-			}
-			return parent, true
+func detailLabel(n *treeNode, styles map[string]lipgloss.Style) string {
+	switch n.Detail {
+	case "error":
+		return styles["failed"].Render(n.Title)
+	case "location":
+		return styles["fileLine"].Render(n.Title)
+	case "attachment":
+		label := styles["test"].Render(n.Title)
+		if n.File != "" {
+			return label + " " + styles["fileLine"].Render(n.File)
+		}
+		return label + styles["fileLine"].Render(" (inline)")
+	case "finding":
+		return findingLabel(n, styles)
+	case "step":
+		if n.Status == "failed" {
+			return styles["failed"].Render(n.Title+" ✘") + styles["counter"].Render(" "+formatDuration(n.Duration))
 		}
-		return nil, false
+		return styles["test"].Render(n.Title) + styles["counter"].Render(" "+formatDuration(n.Duration))
 	}
+	return n.Title
+}
 
-	for _, topSuite := range pwData.Suites {
-		currentFile := topSuite.File
-		if currentFile == "" {
-			continue
+func groupStyle(n *treeNode, styles map[string]lipgloss.Style) lipgloss.Style {
+	switch n.Group {
+	case "tag", "project":
+		return styles[n.Group]
+	case "annotation":
+		if style, ok := styles[n.Title]; ok {
+			return style
 		}
-		label := strings.TrimSpace(emojis.File + " " + currentFile)
-		fileNode := tree.Root(fileNodeStyle.Render(label))
-		if node, ok := processSuite(topSuite, fileNode, currentFile); ok {
-			root.Child(node)
-			totalFiles++
+		return styles["test"]
+	}
+	return styles["suite"]
+}
+
+func specLabel(as *aggSpec, styles map[string]lipgloss.Style, display DisplayOptions) string {
+	tags := sortedKeys(as.Tags)
+	tagStr := ""
+	if display.ShowTags && len(tags) > 0 {
+		tagStr = styles["tag"].Render(" [" + strings.Join(tags, ", ") + "]")
+	}
+
+	projects := sortedKeys(as.Projects)
+	projectStr := ""
+	if display.ShowProjects && len(projects) > 0 {
+		projectStr = styles["project"].Render(" (" + strings.Join(projects, ", ") + ")")
+		if len(as.Outcomes) > 0 {
+			projectStr = projectOutcomes(as, projects, styles)
 		}
 	}
+	outcome := worstOutcome(as)
 
-	counter := fmt.Sprintf("Total: %d test%s in %d file%s",
-		totalTests, pluralize(totalTests), totalFiles, pluralize(totalFiles))
+	titleLabel := as.Title
+	if as.Skipped {
+		titleLabel += " [skipped]"
+	}
+	if as.Fixme {
+		titleLabel += " [fixme]"
+	}
+	if as.Fail {
+		titleLabel += " [fail]"
+	}
+	if outcome != "" && !display.ShowProjects {
+		titleLabel += " [" + outcome + "]"
+	}
+
+	var title string
+	switch {
+	case outcome != "":
+		title = styles[outcomeStyleName(outcome)].Render(titleLabel)
+	case as.Skipped:
+		title = styles["skipped"].Render(titleLabel)
+	case as.Fixme:
+		title = styles["fixme"].Render(titleLabel)
+	case as.Fail:
+		title = styles["fail"].Render(titleLabel)
+	default:
+		title = styles["test"].Render(titleLabel)
+	}
+
+	fileLineStr := ""
+	if display.ShowFileLines {
+		fileLineStr = styles["fileLine"].Render(fmt.Sprintf("(%s:%d)", as.File, as.Line))
+	}
+	return fmt.Sprintf("%s%s%s %s", title, projectStr, tagStr, fileLineStr)
+}
 
-	return "\n" + root.String() + "\n\n" + counterStyle.Render(counter) + "\n"
+// projectOutcomes lists each project with its outcome, duration and retries,
+// e.g. " (chromium: passed 1.2s, webkit: failed 3.0s after 2 retries)".
+func projectOutcomes(as *aggSpec, projects []string, styles map[string]lipgloss.Style) string {
+	var parts []string
+	for _, project := range projects {
+		part := styles["project"].Render(project)
+		if outcome, ok := as.Outcomes[project]; ok {
+			part += styles["project"].Render(": ") + styles[outcomeStyleName(outcome.Status)].Render(outcome.String())
+		}
+		parts = append(parts, part)
+	}
+	sep := styles["project"].Render(", ")
+	return styles["project"].Render(" (") + strings.Join(parts, sep) + styles["project"].Render(")")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var playwrightConfigNames = []string{"playwright.config.ts", "playwright.config.js", "playwright.config.mjs"}
+
+// findPlaywrightConfigs returns the Playwright configs beneath dir. Inside a
+// git repository the files git knows about are used, so ignored directories
+// are skipped; elsewhere the tree is walked without node_modules.
+func findPlaywrightConfigs(dir string) ([]string, error) {
+	var configs []string
+
+	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		for _, file := range strings.Split(string(bytes.TrimSpace(out)), "\n") {
+			if slices.Contains(playwrightConfigNames, filepath.Base(file)) {
+				if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+					configs = append(configs, filepath.Join(dir, file))
+				}
+			}
+		}
+		sort.Strings(configs)
+		return configs, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != dir && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && slices.Contains(playwrightConfigNames, d.Name()) {
+			configs = append(configs, path)
+		}
+		return nil
+	})
+	sort.Strings(configs)
+	return configs, err
+}
+
+type workspaceReport struct {
+	Config string
+	Data   PlaywrightJSON
+	Err    error
+}
+
+// listWorkspace lists the tests of every config concurrently, each from the
+// config's own directory so its dependencies resolve.
+func listWorkspace(configs []string) []workspaceReport {
+	reports := make([]workspaceReport, len(configs))
+
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i].Config = config
+
+			// Projects are filtered afterwards, as each config names its own.
+			args := playwrightListArgs(nil, *onlyChanged, *lastFailed, filepath.Base(config), grepPattern, grepInvert)
+			raw, err := listPlaywright(filepath.Dir(config), args)
+			if err == nil {
+				reports[i].Data, err = loadPlaywrightJSON(raw)
+			}
+			reports[i].Err = err
+		}()
+	}
+	wg.Wait()
+
+	return reports
+}
+
+// buildWorkspaceTree places the tree of each config under a node titled with
+// the config's path.
+func buildWorkspaceTree(reports []workspaceReport) *treeNode {
+	root := &treeNode{Kind: rootNode}
+	for _, report := range reports {
+		nodes := buildNodeTree(report.Data)
+		if len(nodes.Children) == 0 {
+			continue
+		}
+		root.Children = append(root.Children, &treeNode{
+			Kind:     configNode,
+			Title:    report.Config,
+			File:     report.Config,
+			Children: nodes.Children,
+		})
+	}
+	return root
+}
+
+func runWorkspace(command string, styles map[string]lipgloss.Style, display DisplayOptions, emojis DisplayEmojis) {
+	if *interactive || (command != "" && command != "export") {
+		fmt.Println("--workspace only supports the tree, JSON and markdown formats and 'pwtree export'")
+		os.Exit(1)
+	}
+
+	configs, err := findPlaywrightConfigs(".")
+	if err != nil {
+		fmt.Printf("Error finding Playwright configs: %v\n", err)
+		os.Exit(1)
+	}
+	if len(configs) == 0 {
+		fmt.Println("No playwright.config.{ts,js,mjs} found beneath the current directory")
+		os.Exit(1)
+	}
+
+	reports := listWorkspace(configs)
+	for i, report := range reports {
+		if report.Err != nil {
+			fmt.Printf("Error listing tests for %s: %v\n", report.Config, report.Err)
+			os.Exit(1)
+		}
+		reports[i].Data = filterReport(report.Data)
+	}
+	nodes := buildWorkspaceTree(reports)
+
+	var out string
+	switch {
+	case command == "export":
+		if htmlPath == "" {
+			fmt.Println("Usage: pwtree export --html <file path>")
+			os.Exit(1)
+		}
+		page, err := renderHTMLExport(nodes, styles, emojis)
+		if err != nil {
+			fmt.Printf("Error rendering HTML: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(htmlPath, []byte(page), 0644); err != nil {
+			fmt.Printf("Error writing HTML: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Exported tree to", htmlPath)
+		return
+	case outputFormat == "json":
+		if out, err = renderJSONView(nodes); err != nil {
+			fmt.Printf("Error encoding JSON output: %v\n", err)
+			os.Exit(1)
+		}
+		out += "\n"
+	case outputFormat == "markdown":
+		out = renderMarkdownView(nodes, display)
+	default:
+		out = renderTreeView(nodes, styles, display, emojis) + "\n"
+	}
+	fmt.Print(out)
+}
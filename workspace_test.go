@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindPlaywrightConfigs(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"apps/web/playwright.config.ts",
+		"apps/admin/playwright.config.mjs",
+		"apps/admin/node_modules/lib/playwright.config.js",
+		"playwright.config.js",
+		"apps/web/vite.config.ts",
+	} {
+		path := filepath.Join(dir, file)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	configs, err := findPlaywrightConfigs(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		filepath.Join(dir, "apps/admin/playwright.config.mjs"),
+		filepath.Join(dir, "apps/web/playwright.config.ts"),
+		filepath.Join(dir, "playwright.config.js"),
+	}
+	if !reflect.DeepEqual(configs, expected) {
+		t.Errorf("Expected %v, got %v", expected, configs)
+	}
+}
+
+func TestBuildWorkspaceTree(t *testing.T) {
+	report := func(projects ...string) workspaceReport {
+		var tests []TestInstance
+		for _, p := range projects {
+			tests = append(tests, TestInstance{ProjectName: p})
+		}
+		return workspaceReport{Data: PlaywrightJSON{Suites: []Suite{
+			{Title: "login.spec.ts", File: "login.spec.ts", Specs: []Spec{
+				{Title: "logs in", File: "login.spec.ts", Line: 3, Tests: tests},
+			}},
+		}}}
+	}
+	web := report("chromium", "firefox")
+	web.Config = "apps/web/playwright.config.ts"
+	admin := report("chromium")
+	admin.Config = "apps/admin/playwright.config.ts"
+	empty := workspaceReport{Config: "apps/docs/playwright.config.ts"}
+
+	root := buildWorkspaceTree([]workspaceReport{web, admin, empty})
+
+	if got := childTitles(root); !reflect.DeepEqual(got, []string{web.Config, admin.Config}) {
+		t.Fatalf("Expected one node per config with tests, got %v", got)
+	}
+	if tests, files := root.Children[0].totals(); tests != 2 || files != 1 {
+		t.Errorf("Expected 2 tests in 1 file for web, got %d in %d", tests, files)
+	}
+	// The same path in two configs is two different files.
+	if tests, files := root.totals(); tests != 3 || files != 2 {
+		t.Errorf("Expected a grand total of 3 tests in 2 files, got %d in %d", tests, files)
+	}
+}
+
+func TestListWorkspace(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho '{\"suites\":[{\"title\":\"a.spec.ts\",\"file\":\"a.spec.ts\"}]}'\n"
+	if err := os.WriteFile(filepath.Join(bin, "npx"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	configs := []string{filepath.Join(dir, "playwright.config.ts"), filepath.Join(bin, "playwright.config.ts")}
+	reports := listWorkspace(configs)
+
+	for i, report := range reports {
+		if report.Err != nil {
+			t.Fatalf("Unexpected error for %s: %v", report.Config, report.Err)
+		}
+		if report.Config != configs[i] || len(report.Data.Suites) != 1 {
+			t.Errorf("Expected report %d for %s, got %+v", i, configs[i], report)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+)
+
+// listAtRef lists the tests of a git ref, such as origin/main, by checking it
+// out into a temporary worktree and running Playwright there, in the
+// directory matching the current one. The worktree is removed afterwards.
+func listAtRef(ref string, args []string) ([]byte, error) {
+	top, err := gitOutput("", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := gitOutput("", "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := gitOutput("", "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+
+	// The worktree lives in the git directory so that file watchers and
+	// test globs of the working tree never see it.
+	tmp, err := os.MkdirTemp(gitDir, "pwtree-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "worktree")
+
+	if _, err := gitOutput("", "worktree", "add", "--quiet", "--detach", dir, ref); err != nil {
+		return nil, err
+	}
+	defer gitOutput("", "worktree", "remove", "--force", dir)
+
+	// On Ctrl-C, Playwright exits and the worktree is still removed.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	listDir := filepath.Join(dir, prefix)
+	linkNodeModules(top, dir, prefix)
+	return listPlaywright(listDir, args)
+}
+
+// linkNodeModules makes the dependencies installed in the working tree
+// available to the worktree, which has none of its own, by linking each
+// node_modules from the repository root down to the current directory.
+func linkNodeModules(top, worktree, prefix string) {
+	dirs := []string{""}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		for _, part := range strings.Split(prefix, "/") {
+			dirs = append(dirs, filepath.Join(dirs[len(dirs)-1], part))
+		}
+	}
+	for _, rel := range dirs {
+		source := filepath.Join(top, rel, "node_modules")
+		target := filepath.Join(worktree, rel, "node_modules")
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Dir(target)); err != nil {
+			return
+		}
+		os.Symlink(source, target)
+	}
+}
+
+// gitOutput runs git in dir and returns its trimmed output, with git's own
+// message as the error.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=pwtree", "-c", "user.email=pwtree@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestListAtRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// The fake npx prints the committed list, and whether the dependencies
+	// of the working tree are available.
+	bin := t.TempDir()
+	script := "#!/bin/sh\nif [ -e node_modules/.installed ]; then echo installed; fi\ncat list.txt\n"
+	if err := os.WriteFile(filepath.Join(bin, "npx"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	repo := t.TempDir()
+	app := filepath.Join(repo, "apps", "web")
+	os.MkdirAll(filepath.Join(app, "node_modules"), 0755)
+	os.WriteFile(filepath.Join(app, "node_modules", ".installed"), nil, 0644)
+	os.WriteFile(filepath.Join(repo, ".gitignore"), []byte("node_modules\n"), 0644)
+	os.WriteFile(filepath.Join(app, "list.txt"), []byte("committed\n"), 0644)
+	git(t, repo, "init", "--quiet")
+	git(t, repo, "add", "-A")
+	git(t, repo, "commit", "--quiet", "-m", "initial")
+	os.WriteFile(filepath.Join(app, "list.txt"), []byte("modified\n"), 0644)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(app); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	out, err := listAtRef("HEAD", []string{"playwright", "test", "--list"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := string(out); got != "installed\ncommitted\n" {
+		t.Errorf("Expected the committed list with dependencies linked, got %q", got)
+	}
+	if worktrees := git(t, repo, "worktree", "list"); strings.Count(worktrees, "\n") != 1 {
+		t.Errorf("Expected the temporary worktree to be removed, got\n%s", worktrees)
+	}
+
+	if _, err := listAtRef("no-such-ref", nil); err == nil || !strings.Contains(err.Error(), "git worktree") {
+		t.Errorf("Expected git's error for an unknown ref, got %v", err)
+	}
+}